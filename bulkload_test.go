@@ -0,0 +1,47 @@
+package covertree
+
+import (
+	"testing"
+)
+
+func TestNewTreeFromItems(t *testing.T) {
+
+	t.Run("creates a tree containing every item", func(t *testing.T) {
+		points := randomPoints(500)
+		items := make([]interface{}, len(points))
+		for i := range points {
+			items[i] = &points[i]
+		}
+		store := newTestStore()
+
+		tree, err := NewTreeFromItems(store, 2, items, distanceBetweenPoints)
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if expected, actual := len(points), traverseTree(store, false); expected != actual {
+			t.Errorf("Expected %d items in tree but got %d", expected, actual)
+		}
+		for i := range points {
+			results, _ := tree.FindNearest(&points[i], 1, 0)
+			expectSameResults(t, points[i], results, []ItemWithDistance{{Item: &points[i], Distance: 0}})
+		}
+	})
+
+	t.Run("creates a tree which can be recreated from its store using its root distance", func(t *testing.T) {
+		points := randomPoints(200)
+		items := make([]interface{}, len(points))
+		for i := range points {
+			items[i] = &points[i]
+		}
+		store := NewInMemoryStore(distanceBetweenPoints)
+		tree, _ := NewTreeFromItems(store, 2, items, distanceBetweenPoints)
+
+		recreated, _ := NewTreeWithStore(store, 2, tree.RootDistance(), distanceBetweenPoints)
+
+		for i := range points {
+			results, _ := recreated.FindNearest(&points[i], 1, 0)
+			expectSameResults(t, points[i], results, []ItemWithDistance{{Item: &points[i], Distance: 0}})
+		}
+	})
+}
@@ -1,97 +1,69 @@
 package covertree
 
 import (
-	"github.com/mandykoh/go-parallel"
-	"sync/atomic"
+	"context"
+
+	"github.com/mandykoh/go-covertree/typed"
 )
 
 // CompositeTree spreads operations across multiple subtrees for scaling and
 // parallelisation.
+//
+// Each method of CompositeTree behaves as the method of the same name on
+// typed.CompositeTree, for interface{} items.
 type CompositeTree struct {
-	trees       []*Tree
-	insertCount uint32
+	tree *typed.CompositeTree[interface{}]
 }
 
 // FindNearest returns the nearest items in all the subtrees to the specified
-// query item, up to the specified maximum number of results and maximum
-// distance.
-//
-// Subtrees are queried in parallel.
-//
-// Results are returned with their distances from the query item, in order from
-// closest to furthest.
-//
-// If no items are found matching the given criteria, an empty result set is
-// returned.
-//
-// Multiple calls to FindNearest and Insert are safe to make concurrently.
+// query item, as for typed.CompositeTree.FindNearest.
 func (ct *CompositeTree) FindNearest(query interface{}, maxResults int, maxDistance float64) (results []ItemWithDistance, err error) {
+	return ct.tree.FindNearest(query, maxResults, maxDistance)
+}
 
-	subResults := make([][]ItemWithDistance, len(ct.trees))
-	var subErr error
-
-	parallel.RunWorkers(len(subResults), func(workerNum, workerCount int) {
-		results, err := ct.trees[workerNum].FindNearest(query, maxResults, maxDistance)
-		if err != nil {
-			subErr = err
-			return
-		}
-
-		subResults[workerNum] = results
-	})
-	if subErr != nil {
-		return nil, subErr
-	}
-
-	return zipItemsWithDistance(subResults, maxResults), nil
+// FindNearestContext returns the nearest items in all the subtrees to the
+// specified query item, as for typed.CompositeTree.FindNearestContext.
+func (ct *CompositeTree) FindNearestContext(ctx context.Context, query interface{}, maxResults int, maxDistance float64) (results []ItemWithDistance, err error) {
+	return ct.tree.FindNearestContext(ctx, query, maxResults, maxDistance)
 }
 
-// Insert inserts the specified item into one of the subtrees.
-//
-// Multiple calls to FindNearest and Insert are safe to make concurrently.
+// Insert inserts the specified item into one of the subtrees, as for
+// typed.CompositeTree.Insert.
 func (ct *CompositeTree) Insert(item interface{}) (err error) {
-	treeIndex := atomic.AddUint32(&ct.insertCount, 1) % uint32(len(ct.trees))
-	return ct.trees[treeIndex].Insert(item)
+	return ct.tree.Insert(item)
 }
 
-func NewCompositeTree(trees ...*Tree) *CompositeTree {
-	return &CompositeTree{
-		trees: trees,
-	}
+// InsertContext inserts the specified item into one of the subtrees, as for
+// typed.CompositeTree.InsertContext.
+func (ct *CompositeTree) InsertContext(ctx context.Context, item interface{}) (err error) {
+	return ct.tree.InsertContext(ctx, item)
 }
 
-func zipItemsWithDistance(itemSets [][]ItemWithDistance, limit int) []ItemWithDistance {
-	var results []ItemWithDistance
-
-	itemIndices := make([]int, len(itemSets))
-
-	for itemCount := 0; itemCount < limit; itemCount++ {
-
-		// Find the minimum item across all item sets
-		minItemSet := 0
-		var minItem *ItemWithDistance
-
-		for i := range itemSets {
-			itemIndex := itemIndices[i]
-			if itemIndex >= len(itemSets[i]) {
-				continue
-			}
-
-			item := &itemSets[i][itemIndex]
-			if minItem == nil || item.Distance < minItem.Distance {
-				minItem = item
-				minItemSet = i
-			}
-		}
+// Remove removes the given item from whichever subtree contains it, as for
+// typed.CompositeTree.Remove.
+//
+// removed will be the item that was successfully removed, or nil if no matching
+// item was found.
+func (ct *CompositeTree) Remove(item interface{}) (removed interface{}, err error) {
+	removed, _, err = ct.tree.Remove(item)
+	return
+}
 
-		// No minimum item found - no more items, so stop
-		if minItem == nil {
-			break
-		}
+// RemoveContext removes the given item from whichever subtree contains it, as
+// for typed.CompositeTree.RemoveContext.
+//
+// removed will be the item that was successfully removed, or nil if no matching
+// item was found.
+func (ct *CompositeTree) RemoveContext(ctx context.Context, item interface{}) (removed interface{}, err error) {
+	removed, _, err = ct.tree.RemoveContext(ctx, item)
+	return
+}
 
-		itemIndices[minItemSet]++
-		results = append(results, *minItem)
+func NewCompositeTree(trees ...*Tree) *CompositeTree {
+	typedTrees := make([]*typed.Tree[interface{}], len(trees))
+	for i := range trees {
+		typedTrees[i] = trees[i].tree
 	}
 
-	return results
+	return &CompositeTree{tree: typed.NewCompositeTree(typedTrees...)}
 }
@@ -2,6 +2,6 @@ module github.com/mandykoh/go-covertree
 
 // go: no requirements found in Gopkg.lock
 
-go 1.13
+go 1.20
 
 require github.com/mandykoh/go-parallel v0.1.0
@@ -1,10 +1,12 @@
 package covertree
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/rand"
 	"sort"
+	"sync"
 	"testing"
 	"time"
 )
@@ -149,16 +151,10 @@ func linearSearch(query *Point, points []Point, maxResults int, maxDistance floa
 	return results, linearSearchDistanceCalls
 }
 
-func loadRoot(tree *Tree) (root interface{}, rootLevel int, err error) {
-	rootLevels, err := tree.NewTracer().loadChildren(nil)
-	if err != nil {
-		return
-	}
-
-	for level, items := range rootLevels[0].items {
-		root = items[0]
-		rootLevel = level
-		break
+func loadRoot(store *testStore) (root interface{}, rootLevel int) {
+	rootLevel = store.rootLevel()
+	if roots := store.levelsFor(nil)[rootLevel]; len(roots) > 0 {
+		root = roots[0]
 	}
 
 	return
@@ -184,7 +180,7 @@ func randomPoints(count int) (points []Point) {
 	return
 }
 
-func traverseNodes(item, parent interface{}, level int, indentLevel int, store *inMemoryStore, print bool) (nodeCount int) {
+func traverseNodes(item, parent interface{}, level int, indentLevel int, store *testStore, print bool) (nodeCount int) {
 	if print {
 		fmt.Printf("%4d: ", level)
 		for i := 0; i < indentLevel; i++ {
@@ -205,16 +201,16 @@ func traverseNodes(item, parent interface{}, level int, indentLevel int, store *
 
 	nodeCount = 1
 
-	var levels []int
-	for k := range store.levelsFor(item) {
-		levels = append(levels, k)
+	levels := store.levelsFor(item)
+	var levelKeys []int
+	for k := range levels {
+		levelKeys = append(levelKeys, k)
 	}
-	sort.Ints(levels)
+	sort.Ints(levelKeys)
 
-	for i := len(levels) - 1; i >= 0; i-- {
-		l := levels[i]
-		children, _ := store.LoadChildren(item)
-		for _, c := range children[0].itemsAt(l) {
+	for i := len(levelKeys) - 1; i >= 0; i-- {
+		l := levelKeys[i]
+		for _, c := range levels[l] {
 			nodeCount += traverseNodes(c, item, l, indentLevel+1, store, print)
 		}
 	}
@@ -222,41 +218,64 @@ func traverseNodes(item, parent interface{}, level int, indentLevel int, store *
 	return
 }
 
-func traverseTree(tree *Tree, store *inMemoryStore, print bool) (nodeCount int) {
+func traverseTree(store *testStore, print bool) (nodeCount int) {
 	if print {
 		fmt.Println("---")
 	}
 
-	roots, _ := tree.NewTracer().loadChildren(nil)
-
-	for _, root := range roots {
-		for _, rootNode := range root.itemsAt(tree.rootLevel) {
-			nodeCount += traverseNodes(rootNode, nil, tree.rootLevel, 0, store, print)
-		}
+	rootLevel := store.rootLevel()
+	for _, rootNode := range store.levelsFor(nil)[rootLevel] {
+		nodeCount += traverseNodes(rootNode, nil, rootLevel, 0, store, print)
 	}
 	return
 }
 
+// testStore is a Store which keeps its items in maps that tests can inspect
+// directly, and which records how often the tree is saved.
 type testStore struct {
-	inMemoryStore
+	items      map[interface{}]map[int][]interface{}
+	mutex      sync.RWMutex
 	savedCount int
 	savedRoots []interface{}
 }
 
-func newTestStore(distanceFunc DistanceFunc) *testStore {
-	return &testStore{inMemoryStore: *NewInMemoryStore(distanceFunc)}
+func newTestStore() *testStore {
+	return &testStore{items: make(map[interface{}]map[int][]interface{})}
 }
 
 func (ts *testStore) AddItem(item, parent interface{}, level int) error {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
 	ts.savedCount++
 
 	if parent == nil {
 		ts.savedRoots = append(ts.savedRoots, item)
 	}
-	return ts.inMemoryStore.AddItem(item, parent, level)
+
+	levels := ts.levels(parent)
+	levels[level] = append(levels[level], item)
+	return nil
+}
+
+func (ts *testStore) LoadChildren(parents ...interface{}) ([]LevelsWithItems, error) {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	results := make([]LevelsWithItems, len(parents))
+	for i := range parents {
+		for level, items := range ts.items[parents[i]] {
+			results[i].Set(level, items)
+		}
+	}
+
+	return results, nil
 }
 
 func (ts *testStore) RemoveItem(item, parent interface{}, level int) error {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
 	ts.savedCount++
 
 	if parent == nil {
@@ -267,10 +286,23 @@ func (ts *testStore) RemoveItem(item, parent interface{}, level int) error {
 			}
 		}
 	}
-	return ts.inMemoryStore.RemoveItem(item, parent, level)
+
+	levels := ts.items[parent]
+	for i, levelItem := range levels[level] {
+		if levelItem == item {
+			levels[level] = append(levels[level][:i], levels[level][i+1:]...)
+			delete(ts.items, item)
+			return nil
+		}
+	}
+
+	return nil
 }
 
 func (ts *testStore) UpdateItem(item, parent interface{}, level int) error {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
 	ts.savedCount++
 
 	if parent == nil {
@@ -284,8 +316,20 @@ func (ts *testStore) UpdateItem(item, parent interface{}, level int) error {
 		if !found {
 			ts.savedRoots = append(ts.savedRoots, item)
 		}
+
+		for level, roots := range ts.items[nil] {
+			for i := range roots {
+				if roots[i] == item {
+					ts.items[nil][level] = append(roots[:i], roots[i+1:]...)
+					break
+				}
+			}
+		}
 	}
-	return ts.inMemoryStore.UpdateItem(item, parent, level)
+
+	levels := ts.levels(parent)
+	levels[level] = append(levels[level], item)
+	return nil
 }
 
 func (ts *testStore) expectSavedTree(t *testing.T, saveCount int, roots []interface{}, rootLevel int) {
@@ -305,8 +349,70 @@ func (ts *testStore) expectSavedTree(t *testing.T, saveCount int, roots []interf
 	}
 }
 
+func (ts *testStore) levels(item interface{}) map[int][]interface{} {
+	levels, ok := ts.items[item]
+	if !ok {
+		levels = make(map[int][]interface{})
+		ts.items[item] = levels
+	}
+
+	return levels
+}
+
+func (ts *testStore) levelsFor(item interface{}) map[int][]interface{} {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	return ts.levels(item)
+}
+
+// rootLevel returns the highest level at which the store has roots.
+func (ts *testStore) rootLevel() (level int) {
+	first := true
+	for l, roots := range ts.levelsFor(nil) {
+		if len(roots) > 0 && (first || l > level) {
+			level = l
+			first = false
+		}
+	}
+
+	return
+}
+
 type Point [3]float64
 
 func (p *Point) String() string {
 	return fmt.Sprintf("[%g %g %g]", p[0], p[1], p[2])
 }
+
+// recordingStore is a Store implemented outside the typed package, which
+// records the parents and contexts it is given.
+type recordingStore struct {
+	*inMemoryStore
+	parents  []interface{}
+	contexts []context.Context
+}
+
+func newRecordingStore() *recordingStore {
+	return &recordingStore{inMemoryStore: NewInMemoryStore(distanceBetweenPoints)}
+}
+
+func (s *recordingStore) AddItem(item, parent interface{}, level int) error {
+	s.parents = append(s.parents, parent)
+	return s.inMemoryStore.AddItem(item, parent, level)
+}
+
+func (s *recordingStore) AddItemContext(ctx context.Context, item, parent interface{}, level int) error {
+	s.contexts = append(s.contexts, ctx)
+	return s.AddItem(item, parent, level)
+}
+
+func (s *recordingStore) LoadChildrenContext(ctx context.Context, parents ...interface{}) ([]LevelsWithItems, error) {
+	s.contexts = append(s.contexts, ctx)
+	return s.LoadChildren(parents...)
+}
+
+func (s *recordingStore) RemoveItemContext(ctx context.Context, item, parent interface{}, level int) error {
+	s.contexts = append(s.contexts, ctx)
+	return s.RemoveItem(item, parent, level)
+}
@@ -16,166 +16,124 @@ func TestInMemoryStore(t *testing.T) {
 		return math.Abs(a.(*dummyItem).value - b.(*dummyItem).value)
 	}
 
+	// Trees are created with a root distance of 1024, putting their roots at
+	// level 10.
+	find := func(t *testing.T, s Store, item *dummyItem) []ItemWithDistance {
+		t.Helper()
+
+		tree, _ := NewTreeWithStore(s, 2, 1024.0, distanceBetween)
+		results, err := tree.FindNearest(item, 2, 0)
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+
+		return results
+	}
+
 	t.Run("AddItem()", func(t *testing.T) {
 
 		t.Run("adds an entry for a new child item", func(t *testing.T) {
-			item := &dummyItem{"child", 123.0}
+			item := &dummyItem{"child", 460.0}
 			parent := &dummyItem{"parent", 456.0}
 
-			s := NewInMemoryStore(nil)
+			s := NewInMemoryStore(distanceBetween)
+			_ = s.AddItem(parent, nil, 10)
 			_ = s.AddItem(item, parent, 5)
 
-			levels, ok := s.items[parent]
-			if !ok {
-				t.Fatalf("Expected levels to exist for parent %v", parent)
-			}
-			if actual, expected := len(levels[5]), 1; actual != expected {
-				t.Errorf("Expected 1 item in level but found %d", actual)
+			results := find(t, s, item)
+
+			if actual, expected := len(results), 1; actual != expected {
+				t.Fatalf("Expected %d item but found %d", expected, actual)
 			}
-			if actual, expected := levels[5][0].(*dummyItem), item; actual != expected {
-				t.Errorf("Expected item %f but found %f", expected.value, actual.value)
+			if actual, expected := results[0].Item, item; actual != expected {
+				t.Errorf("Expected item %v but found %v", expected, actual)
 			}
 		})
 	})
 
 	t.Run("LoadChildren()", func(t *testing.T) {
-		parent := &dummyItem{"parent", 456.0}
-		item1 := &dummyItem{"thing1", 123.0}
-		item2 := &dummyItem{"thing2", 234.0}
-
-		s := inMemoryStore{
-			items: map[interface{}]map[int][]interface{}{
-				parent: {
-					7: {item1, item2},
-				},
-			},
-		}
-
-		t.Run("retrieves existing child items", func(t *testing.T) {
-			allChildren, _ := s.LoadChildren(parent)
-			items := allChildren[0].itemsAt(7)
-
-			if actual, expected := len(items), 2; actual != expected {
-				t.Errorf("Expected %d items but found %d", expected, actual)
-			} else {
-				if actual, expected := items[0], item1; actual != expected {
-					t.Errorf("Expected item '%s' but found %v", expected.id, actual)
-				}
-				if actual, expected := items[1], item2; actual != expected {
-					t.Errorf("Expected item '%s' but found %v", expected.id, actual)
-				}
-			}
-		})
 
-		t.Run("returns empty results for non-existent parent", func(t *testing.T) {
+		t.Run("returns an entry for each parent, including non-existent parents", func(t *testing.T) {
+			parent := &dummyItem{"parent", 456.0}
 			badParent := &dummyItem{"bad parent", 456.0}
 
-			allChildren, _ := s.LoadChildren(badParent)
-
-			if actual, expected := len(allChildren[0].items), 0; actual != expected {
-				t.Errorf("Expected %d items but found %d", expected, actual)
-			}
-		})
-
-		t.Run("returns empty results for non-existent children", func(t *testing.T) {
-			parent := &dummyItem{"parent", 456.0}
+			s := NewInMemoryStore(distanceBetween)
+			_ = s.AddItem(&dummyItem{"thing1", 460.0}, parent, 7)
 
-			allChildren, _ := s.LoadChildren(parent)
-			items := allChildren[0].itemsAt(5)
+			allChildren, err := s.LoadChildren(parent, badParent)
 
-			if actual, expected := len(items), 0; actual != expected {
-				t.Errorf("Expected %d items but found %d", expected, actual)
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if actual, expected := len(allChildren), 2; actual != expected {
+				t.Errorf("Expected %d entries but found %d", expected, actual)
 			}
 		})
 	})
 
 	t.Run("RemoveItem()", func(t *testing.T) {
-		parent := &dummyItem{"parent", 456.0}
-		item1 := &dummyItem{"thing1", 123.0}
-		item2 := &dummyItem{"thing2", 234.0}
-
-		setup := func() inMemoryStore {
-			return inMemoryStore{
-				distanceBetween: distanceBetween,
-				items: map[interface{}]map[int][]interface{}{
-					parent: {
-						7: {item1, item2},
-					},
-					item2: {},
-				},
-			}
-		}
 
 		t.Run("removes an existing child", func(t *testing.T) {
-			s := setup()
-			_ = s.RemoveItem(item2, parent, 7)
+			parent := &dummyItem{"parent", 456.0}
+			item1 := &dummyItem{"thing1", 460.0}
+			item2 := &dummyItem{"thing2", 470.0}
 
-			items := s.levelsFor(parent)[7]
+			s := NewInMemoryStore(distanceBetween)
+			_ = s.AddItem(parent, nil, 10)
+			_ = s.AddItem(item1, parent, 7)
+			_ = s.AddItem(item2, parent, 7)
 
-			if expected, actual := 1, len(items); expected != actual {
-				t.Errorf("Expected one child item after deletion but got %d", actual)
-			}
-			if expected, actual := item1, items[0]; expected != actual {
-				t.Errorf("Expected remaining child to be %v but was %v", expected, actual)
+			_ = s.RemoveItem(item2, parent, 7)
+
+			if actual, expected := len(find(t, s, item1)), 1; actual != expected {
+				t.Errorf("Expected remaining child to be found but found %d items", actual)
 			}
-			if _, childEntriesExist := s.items[item2]; childEntriesExist {
-				t.Errorf("Expected child entries for removed item to be deleted")
+			if actual, expected := len(find(t, s, item2)), 0; actual != expected {
+				t.Errorf("Expected removed child not to be found but found %d items", actual)
 			}
 		})
 	})
 
 	t.Run("UpdateItem()", func(t *testing.T) {
 
-		t.Run("saves node at the root", func(t *testing.T) {
-			item := &dummyItem{"child", 123.0}
+		t.Run("moves a root to its new level", func(t *testing.T) {
+			item := &dummyItem{"root", 123.0}
 
-			s := NewInMemoryStore(nil)
+			s := NewInMemoryStore(distanceBetween)
+			_ = s.UpdateItem(item, nil, 10)
 			_ = s.UpdateItem(item, nil, 5)
 
-			levels, ok := s.items[nil]
-			if !ok {
-				t.Fatalf("Expected levels to exist at root")
-			}
-			if actual, expected := len(levels[5]), 1; actual != expected {
-				t.Errorf("Expected 1 item in level but found %d", actual)
-			}
-			if actual, expected := levels[5][0].(*dummyItem), item; actual != expected {
-				t.Errorf("Expected item %f but found %f", expected.value, actual.value)
+			if actual, expected := len(find(t, s, item)), 0; actual != expected {
+				t.Errorf("Expected item to be gone from previous level but found %d items", actual)
 			}
 
-			_ = s.UpdateItem(item, nil, 7)
+			_ = s.UpdateItem(item, nil, 10)
 
-			levels, ok = s.items[nil]
-			if !ok {
-				t.Fatalf("Expected levels to exist at root")
-			}
-			if actual, expected := len(levels[5]), 0; actual != expected {
-				t.Errorf("Expected item to be gone from previous level but found %d", actual)
-			}
-			if actual, expected := len(levels[7]), 1; actual != expected {
-				t.Errorf("Expected 1 item in level but found %d", actual)
+			results := find(t, s, item)
+
+			if actual, expected := len(results), 1; actual != expected {
+				t.Fatalf("Expected %d item but found %d", expected, actual)
 			}
-			if actual, expected := levels[7][0].(*dummyItem), item; actual != expected {
-				t.Errorf("Expected item %f but found %f", expected.value, actual.value)
+			if actual, expected := results[0].Item, item; actual != expected {
+				t.Errorf("Expected item %v but found %v", expected, actual)
 			}
 		})
 
 		t.Run("adds item as a child of the parent", func(t *testing.T) {
-			item := &dummyItem{"child", 123.0}
+			item := &dummyItem{"child", 460.0}
 			parent := &dummyItem{"parent", 456.0}
 
-			s := NewInMemoryStore(nil)
+			s := NewInMemoryStore(distanceBetween)
+			_ = s.UpdateItem(parent, nil, 10)
 			_ = s.UpdateItem(item, parent, 5)
 
-			levels, ok := s.items[parent]
-			if !ok {
-				t.Fatalf("Expected levels to exist for parent %v", parent)
-			}
-			if actual, expected := len(levels[5]), 1; actual != expected {
-				t.Errorf("Expected 1 item in level but found %d", actual)
+			results := find(t, s, item)
+
+			if actual, expected := len(results), 1; actual != expected {
+				t.Fatalf("Expected %d item but found %d", expected, actual)
 			}
-			if actual, expected := levels[5][0].(*dummyItem), item; actual != expected {
-				t.Errorf("Expected item %f but found %f", expected.value, actual.value)
+			if actual, expected := results[0].Item, item; actual != expected {
+				t.Errorf("Expected item %v but found %v", expected, actual)
 			}
 		})
 	})
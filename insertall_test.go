@@ -0,0 +1,55 @@
+package covertree
+
+import (
+	"errors"
+	"testing"
+)
+
+type selectivelyFailingStore struct {
+	*testStore
+	failingItems map[interface{}]bool
+	err          error
+}
+
+func (s *selectivelyFailingStore) AddItem(item, parent interface{}, level int) error {
+	if s.failingItems[item] {
+		return s.err
+	}
+	return s.testStore.AddItem(item, parent, level)
+}
+
+func TestTreeInsertAll(t *testing.T) {
+
+	t.Run("returns failures with their items and inserts the remaining items", func(t *testing.T) {
+		storeErr := errors.New("store failure")
+		points := randomPoints(100)
+		items := make([]interface{}, len(points))
+		for i := range points {
+			items[i] = &points[i]
+		}
+		failingItems := map[interface{}]bool{&points[0]: true, &points[50]: true, &points[99]: true}
+		store := &selectivelyFailingStore{newTestStore(), failingItems, storeErr}
+		tree, _ := NewTreeWithStore(store, 2, 1000.0, distanceBetweenPoints)
+
+		err := tree.InsertAll(items, 4, nil)
+
+		var insertErrs InsertErrors
+		if !errors.As(err, &insertErrs) {
+			t.Fatalf("Expected InsertErrors but got %v", err)
+		}
+		if expected, actual := len(failingItems), len(insertErrs); expected != actual {
+			t.Fatalf("Expected %d failures but got %d", expected, actual)
+		}
+		for _, e := range insertErrs {
+			if !failingItems[e.Item] {
+				t.Errorf("Expected failure for one of the failing items but got %v", e.Item)
+			}
+			if !errors.Is(e, storeErr) {
+				t.Errorf("Expected error %v but got %v", storeErr, e.Err)
+			}
+		}
+		if expected, actual := len(points)-len(failingItems), traverseTree(store.testStore, false); expected != actual {
+			t.Errorf("Expected %d items in tree but got %d", expected, actual)
+		}
+	})
+}
@@ -5,6 +5,40 @@ import (
 	"testing"
 )
 
+// countingStore is an in-memory Store which counts the items given to each of
+// its operations.
+type countingStore struct {
+	*inMemoryStore
+	added   int
+	loaded  int
+	removed int
+	updated int
+}
+
+func newCountingStore() *countingStore {
+	return &countingStore{inMemoryStore: NewInMemoryStore(distanceBetweenPoints)}
+}
+
+func (s *countingStore) AddItem(item, parent interface{}, level int) error {
+	s.added++
+	return s.inMemoryStore.AddItem(item, parent, level)
+}
+
+func (s *countingStore) LoadChildren(parents ...interface{}) ([]LevelsWithItems, error) {
+	s.loaded += len(parents)
+	return s.inMemoryStore.LoadChildren(parents...)
+}
+
+func (s *countingStore) RemoveItem(item, parent interface{}, level int) error {
+	s.removed++
+	return s.inMemoryStore.RemoveItem(item, parent, level)
+}
+
+func (s *countingStore) UpdateItem(item, parent interface{}, level int) error {
+	s.updated++
+	return s.inMemoryStore.UpdateItem(item, parent, level)
+}
+
 func TestPartitionedStore(t *testing.T) {
 
 	partitioningFunc := func(parentItem interface{}) string {
@@ -27,79 +61,63 @@ func TestPartitionedStore(t *testing.T) {
 		}
 	}
 
-	countItems := func(items map[interface{}]map[int][]interface{}) int {
-		total := 0
+	newStores := func() (*countingStore, *countingStore, *countingStore, *countingStore) {
+		return newCountingStore(), newCountingStore(), newCountingStore(), newCountingStore()
+	}
+
+	expectDistributed := func(t *testing.T, operation string, total int, counts ...int) {
+		t.Helper()
 
-		for _, levels := range items {
-			for _, children := range levels {
-				total += len(children)
+		sum := 0
+		for i, count := range counts {
+			if count == 0 {
+				t.Errorf("Expected some %s operations on store %d but found none", operation, i)
 			}
+			sum += count
+		}
+		if expected, actual := total, sum; expected != actual {
+			t.Errorf("Expected %s operations on all stores to total %d but was %d", operation, expected, actual)
 		}
-
-		return total
 	}
 
 	t.Run("consistently uses the same stores for items", func(t *testing.T) {
-		s1 := NewInMemoryStore(distanceBetweenPoints)
-		s2 := NewInMemoryStore(distanceBetweenPoints)
-		s := NewPartitionedStore(partitioningFunc, s1, s2)
+		s1, s2, s3, s4 := newStores()
+		s := NewPartitionedStore(partitioningFunc, s1, s2, s3, s4)
 
 		points := randomPoints(100)
+		addPoints(points, s, t)
 
-		stores := make([]Store, len(points))
 		for i := range points {
-			stores[i], _ = s.storeForParent(&points[i])
+			var parent interface{}
+			if i > 0 {
+				parent = &points[i-1]
+			}
 
-			if stores[i] != s1 && stores[i] != s2 {
-				t.Fatalf("Expected returned store to be one of the provided stores but was %v", stores[i])
+			err := s.RemoveItem(&points[i], parent, i)
+			if err != nil {
+				t.Fatalf("Expected point to be removed but got error: %v", err)
 			}
 		}
 
-		for i := range points {
-			store, _ := s.storeForParent(&points[i])
-			if store != stores[i] {
-				t.Fatalf("Expected point %d to use store %v but was %v", i, stores[i], store)
+		for i, store := range []*countingStore{s1, s2, s3, s4} {
+			if expected, actual := store.added, store.removed; expected != actual {
+				t.Errorf("Expected store %d to have %d points removed but was %d", i, expected, actual)
 			}
 		}
 	})
 
 	t.Run("distributes AddItem operations across underlying stores", func(t *testing.T) {
-		s1 := NewInMemoryStore(distanceBetweenPoints)
-		s2 := NewInMemoryStore(distanceBetweenPoints)
-		s3 := NewInMemoryStore(distanceBetweenPoints)
-		s4 := NewInMemoryStore(distanceBetweenPoints)
+		s1, s2, s3, s4 := newStores()
 		s := NewPartitionedStore(partitioningFunc, s1, s2, s3, s4)
 
 		points := randomPoints(100)
 		addPoints(points, s, t)
 
-		s1ItemCount := len(s1.items)
-		s2ItemCount := len(s2.items)
-		s3ItemCount := len(s3.items)
-		s4ItemCount := len(s4.items)
-
-		if s1ItemCount == 0 {
-			t.Errorf("Expected some points in the first store but found none")
-		}
-		if s2ItemCount == 0 {
-			t.Errorf("Expected some points in the second store but found none")
-		}
-		if s3ItemCount == 0 {
-			t.Errorf("Expected some points in the third store but found none")
-		}
-		if s4ItemCount == 0 {
-			t.Errorf("Expected some points in the fourth store but found none")
-		}
-		if expected, actual := len(points), s1ItemCount+s2ItemCount+s3ItemCount+s4ItemCount; expected != actual {
-			t.Errorf("Expected points in all stores to total %d but was %d", expected, actual)
-		}
+		expectDistributed(t, "AddItem", len(points), s1.added, s2.added, s3.added, s4.added)
 	})
 
 	t.Run("distributes LoadChildren operations across underlying stores", func(t *testing.T) {
-		s1 := NewInMemoryStore(distanceBetweenPoints)
-		s2 := NewInMemoryStore(distanceBetweenPoints)
-		s3 := NewInMemoryStore(distanceBetweenPoints)
-		s4 := NewInMemoryStore(distanceBetweenPoints)
+		s1, s2, s3, s4 := newStores()
 		s := NewPartitionedStore(partitioningFunc, s1, s2, s3, s4)
 
 		points := randomPoints(100)
@@ -118,13 +136,11 @@ func TestPartitionedStore(t *testing.T) {
 		if expected, actual := len(points), len(children); expected != actual {
 			t.Errorf("Expected children to be returned in %d levels but got %d", expected, actual)
 		}
+		expectDistributed(t, "LoadChildren", len(parents), s1.loaded, s2.loaded, s3.loaded, s4.loaded)
 	})
 
 	t.Run("distributes RemoveItem operations across underlying stores", func(t *testing.T) {
-		s1 := NewInMemoryStore(distanceBetweenPoints)
-		s2 := NewInMemoryStore(distanceBetweenPoints)
-		s3 := NewInMemoryStore(distanceBetweenPoints)
-		s4 := NewInMemoryStore(distanceBetweenPoints)
+		s1, s2, s3, s4 := newStores()
 		s := NewPartitionedStore(partitioningFunc, s1, s2, s3, s4)
 
 		points := randomPoints(100)
@@ -142,25 +158,11 @@ func TestPartitionedStore(t *testing.T) {
 			}
 		}
 
-		if pointCount := countItems(s1.items); pointCount > 0 {
-			t.Errorf("Expected first store to be empty but found %d points", pointCount)
-		}
-		if pointCount := countItems(s2.items); pointCount > 0 {
-			t.Errorf("Expected second store to be empty but found %d points", pointCount)
-		}
-		if pointCount := countItems(s3.items); pointCount > 0 {
-			t.Errorf("Expected third store to be empty but found %d points", pointCount)
-		}
-		if pointCount := countItems(s4.items); pointCount > 0 {
-			t.Errorf("Expected fourth store to be empty but found %d points", pointCount)
-		}
+		expectDistributed(t, "RemoveItem", len(points), s1.removed, s2.removed, s3.removed, s4.removed)
 	})
 
 	t.Run("distributes UpdateItem operations across underlying stores", func(t *testing.T) {
-		s1 := NewInMemoryStore(distanceBetweenPoints)
-		s2 := NewInMemoryStore(distanceBetweenPoints)
-		s3 := NewInMemoryStore(distanceBetweenPoints)
-		s4 := NewInMemoryStore(distanceBetweenPoints)
+		s1, s2, s3, s4 := newStores()
 		s := NewPartitionedStore(partitioningFunc, s1, s2, s3, s4)
 
 		points := randomPoints(100)
@@ -173,6 +175,8 @@ func TestPartitionedStore(t *testing.T) {
 			}
 		}
 
+		expectDistributed(t, "UpdateItem", len(points), s1.updated, s2.updated, s3.updated, s4.updated)
+
 		for i := range points {
 			children, err := s.LoadChildren(&points[i])
 			if err != nil {
@@ -182,12 +186,6 @@ func TestPartitionedStore(t *testing.T) {
 			if expected, actual := 1, len(children); expected != actual {
 				t.Fatalf("Expected one set of children for point %d but found %d", i, actual)
 			}
-			if actual := len(children[0].items); actual == 0 {
-				t.Fatalf("Expected at least one level of children for point %d but found %d", i, actual)
-			}
-			if expected, actual := 1, len(children[0].items[0]); expected != actual {
-				t.Fatalf("Expected one child item at level 0 for point %d but found %d", i, actual)
-			}
 		}
 	})
 }
@@ -1,5 +1,11 @@
 package covertree
 
+import (
+	"context"
+
+	"github.com/mandykoh/go-covertree/typed"
+)
+
 // Store implementations allow entire Trees to be made accessible in an
 // extensible way. Implementations may provide capabilities such as persistence
 // and serialisation to various formats and data stores.
@@ -47,3 +53,167 @@ type Store interface {
 	// items which have previously been added via AddItem.
 	UpdateItem(item, parent interface{}, level int) error
 }
+
+// ContextStore may optionally be implemented by Stores which can abandon their
+// operations when a context is cancelled or its deadline expires, such as
+// those backed by remote databases.
+//
+// Context-aware Tree operations (such as FindNearestContext) use these methods
+// in preference to their Store equivalents when they are available. UpdateItem
+// has no context-aware equivalent, as it is only used to re-parent the orphaned
+// children of a removed item, which must be completed once removal has begun.
+type ContextStore interface {
+	Store
+
+	// AddItemContext is the context-aware equivalent of AddItem.
+	AddItemContext(ctx context.Context, item, parent interface{}, level int) error
+
+	// LoadChildrenContext is the context-aware equivalent of LoadChildren.
+	LoadChildrenContext(ctx context.Context, parents ...interface{}) (children []LevelsWithItems, err error)
+
+	// RemoveItemContext is the context-aware equivalent of RemoveItem.
+	RemoveItemContext(ctx context.Context, item, parent interface{}, level int) error
+}
+
+// SubtreeSizeStore may optionally be implemented by Stores which can report the
+// number of items beneath items in the tree without loading them all, such as
+// those which maintain counts as items are added and removed.
+//
+// Operations which only need to know how many items a subtree contains (such
+// as CountWithin) use this in preference to loading the subtree.
+type SubtreeSizeStore interface {
+	Store
+
+	// LoadSubtreeSizes returns the number of items in the subtree of each of
+	// the specified items, including the item itself. The sizes are expected to
+	// be returned in the same order as the items.
+	LoadSubtreeSizes(items ...interface{}) (sizes []int, err error)
+}
+
+// typedStore adapts a Store for use by a typed.Tree. Context-aware operations
+// fall back to their Store equivalents unless the Store is a ContextStore.
+type typedStore struct {
+	store Store
+}
+
+func (s typedStore) AddItem(item interface{}, parent *interface{}, level int) error {
+	return s.store.AddItem(item, parentItem(parent), level)
+}
+
+func (s typedStore) AddItemContext(ctx context.Context, item interface{}, parent *interface{}, level int) error {
+	if cs, ok := s.store.(ContextStore); ok {
+		return cs.AddItemContext(ctx, item, parentItem(parent), level)
+	}
+	return s.AddItem(item, parent, level)
+}
+
+func (s typedStore) LoadChildren(parents ...*interface{}) ([]LevelsWithItems, error) {
+	return s.store.LoadChildren(parentItems(parents)...)
+}
+
+func (s typedStore) LoadChildrenContext(ctx context.Context, parents ...*interface{}) ([]LevelsWithItems, error) {
+	if cs, ok := s.store.(ContextStore); ok {
+		return cs.LoadChildrenContext(ctx, parentItems(parents)...)
+	}
+	return s.LoadChildren(parents...)
+}
+
+func (s typedStore) RemoveItem(item interface{}, parent *interface{}, level int) error {
+	return s.store.RemoveItem(item, parentItem(parent), level)
+}
+
+func (s typedStore) RemoveItemContext(ctx context.Context, item interface{}, parent *interface{}, level int) error {
+	if cs, ok := s.store.(ContextStore); ok {
+		return cs.RemoveItemContext(ctx, item, parentItem(parent), level)
+	}
+	return s.RemoveItem(item, parent, level)
+}
+
+func (s typedStore) UpdateItem(item interface{}, parent *interface{}, level int) error {
+	return s.store.UpdateItem(item, parentItem(parent), level)
+}
+
+// typedSubtreeSizeStore adapts a SubtreeSizeStore for use by a typed.Tree.
+type typedSubtreeSizeStore struct {
+	typedStore
+}
+
+func (s typedSubtreeSizeStore) LoadSubtreeSizes(items ...interface{}) ([]int, error) {
+	return s.store.(SubtreeSizeStore).LoadSubtreeSizes(items...)
+}
+
+// untypedStore adapts a typed.Store, such as one provided by the typed
+// package, for use as a Store.
+type untypedStore[S typed.Store[interface{}]] struct {
+	store S
+}
+
+func (s *untypedStore[S]) AddItem(item, parent interface{}, level int) error {
+	return s.store.AddItem(item, typedParent(parent), level)
+}
+
+func (s *untypedStore[S]) LoadChildren(parents ...interface{}) ([]LevelsWithItems, error) {
+	return s.store.LoadChildren(typedParents(parents)...)
+}
+
+func (s *untypedStore[S]) RemoveItem(item, parent interface{}, level int) error {
+	return s.store.RemoveItem(item, typedParent(parent), level)
+}
+
+func (s *untypedStore[S]) UpdateItem(item, parent interface{}, level int) error {
+	return s.store.UpdateItem(item, typedParent(parent), level)
+}
+
+// typedStoreFor returns the typed.Store for the specified Store, unwrapping
+// stores which were provided by the typed package rather than adapting them a
+// second time.
+func typedStoreFor(store Store) typed.Store[interface{}] {
+	switch s := store.(type) {
+	case *inMemoryStore:
+		return s.store
+	case *partitionedStore:
+		return s.store
+	case SubtreeSizeStore:
+		return typedSubtreeSizeStore{typedStore{s}}
+	}
+	return typedStore{store}
+}
+
+// parentItem returns the item which a typed parent points to, or nil for the
+// root of the tree.
+func parentItem(parent *interface{}) interface{} {
+	if parent == nil {
+		return nil
+	}
+	return *parent
+}
+
+func parentItems(parents []*interface{}) []interface{} {
+	items := make([]interface{}, len(parents))
+	for i := range parents {
+		items[i] = parentItem(parents[i])
+	}
+
+	return items
+}
+
+// typedParent returns a pointer to a parent item for a typed.Store, or nil for
+// the root of the tree. As for any typed.Store, the pointer identifies the
+// parent only by the item it points to.
+func typedParent(parent interface{}) *interface{} {
+	if parent == nil {
+		return nil
+	}
+	return &parent
+}
+
+func typedParents(parents []interface{}) []*interface{} {
+	pointers := make([]*interface{}, len(parents))
+	for i := range parents {
+		if parents[i] != nil {
+			pointers[i] = &parents[i]
+		}
+	}
+
+	return pointers
+}
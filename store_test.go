@@ -0,0 +1,104 @@
+package covertree
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+type contextKey struct{}
+
+func TestStore(t *testing.T) {
+
+	t.Run("custom stores", func(t *testing.T) {
+
+		t.Run("are given untyped parents, with nil for the root", func(t *testing.T) {
+			store := newRecordingStore()
+			tree, _ := NewTreeWithStore(store, 2, 1000.0, distanceBetweenPoints)
+			points := randomPoints(50)
+
+			_, _ = insertPoints(points, tree)
+
+			if expected, actual := len(points), len(store.parents); expected != actual {
+				t.Fatalf("Expected %d items to be added but got %d", expected, actual)
+			}
+			if store.parents[0] != nil {
+				t.Errorf("Expected the first item to be added as a root but got parent %v", store.parents[0])
+			}
+			for _, parent := range store.parents[1:] {
+				if _, ok := parent.(*Point); parent != nil && !ok {
+					t.Errorf("Expected parent to be a *Point but got %T", parent)
+				}
+			}
+		})
+
+		t.Run("are given contexts when they implement ContextStore", func(t *testing.T) {
+			store := newRecordingStore()
+			tree, _ := NewTreeWithStore(store, 2, 1000.0, distanceBetweenPoints)
+			ctx := context.WithValue(context.Background(), contextKey{}, "value")
+
+			_ = tree.InsertContext(ctx, &Point{1, 2, 3})
+
+			if len(store.contexts) == 0 {
+				t.Fatalf("Expected the store to be given a context")
+			}
+			for _, actual := range store.contexts {
+				if actual != ctx {
+					t.Errorf("Expected context %v but got %v", ctx, actual)
+				}
+			}
+		})
+
+		t.Run("report subtree sizes when they implement SubtreeSizeStore", func(t *testing.T) {
+			store := newRecordingStore()
+			tree, _ := NewTreeWithStore(store, 2, 1000.0, distanceBetweenPoints)
+			points := randomPoints(200)
+			_, _ = insertPoints(points, tree)
+			query := randomPoint()
+
+			count, err := tree.CountWithin(&query, 400)
+			expectedResults, _ := linearSearch(&query, points, len(points), 400)
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if expected, actual := len(expectedResults), count; expected != actual {
+				t.Errorf("Expected %d items within range but got %d", expected, actual)
+			}
+		})
+	})
+
+	t.Run("NewPartitionedStore()", func(t *testing.T) {
+
+		t.Run("partitions by untyped parents across stores", func(t *testing.T) {
+			stores := []Store{NewInMemoryStore(distanceBetweenPoints), newRecordingStore()}
+			partitioningFunc := func(parent interface{}) string {
+				if parent == nil {
+					return ""
+				}
+				return fmt.Sprintf("%v", parent.(*Point))
+			}
+			store := NewPartitionedStore(partitioningFunc, stores...)
+			tree, _ := NewTreeWithStore(store, 2, 1000.0, distanceBetweenPoints)
+			points := randomPoints(200)
+			_, _ = insertPoints(points, tree)
+
+			for i := 0; i < 20; i++ {
+				query := randomPoint()
+
+				results, err := tree.FindNearest(&query, 5, 300)
+
+				if err != nil {
+					t.Fatalf("Expected success but got error: %v", err)
+				}
+				expectedResults, _ := linearSearch(&query, points, 5, 300)
+				if expected, actual := len(expectedResults), len(results); expected != actual {
+					t.Errorf("Expected %d results but got %d", expected, actual)
+				}
+			}
+			if len(stores[1].(*recordingStore).parents) == 0 {
+				t.Errorf("Expected items to be added to every store")
+			}
+		})
+	})
+}
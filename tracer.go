@@ -1,17 +1,22 @@
 package covertree
 
 import (
-	"fmt"
+	"context"
 	"time"
+
+	"github.com/mandykoh/go-covertree/typed"
 )
 
 // Tracer represents a record for performance metrics of Tree operations.
 //
+// Each method of Tracer behaves as the method of the same name on
+// typed.Tracer, for interface{} items, and records the same metrics.
+//
 // Tracers for a given tree can be created using the tree’s NewTracer method.
 //
 // Tracers are not thread safe and should not be shared by multiple Goroutines.
 type Tracer struct {
-	tree                  *Tree
+	tracer                *typed.Tracer[interface{}]
 	TotalCoveredSetSize   int
 	MaxCoverSetSize       int
 	MaxLevelsTraversed    int
@@ -20,90 +25,127 @@ type Tracer struct {
 	TotalTime             time.Duration
 }
 
-// FindNearest returns the nearest items in the tree to the specified query
-// item, up to the specified maximum number of results and maximum distance.
-//
-// Results are returned with their distances from the query item, in order from
-// closest to furthest.
-//
-// If no items are found matching the given criteria, an empty result set is
-// returned.
-func (t *Tracer) FindNearest(query interface{}, maxResults int, maxDistance float64) (results []ItemWithDistance, err error) {
-	t.doWithTrace(func() {
-		results, err = t.tree.findNearestWithTrace(query, maxResults, maxDistance, t)
-	})
-	return
+// CountWithin returns the number of items in the tree which are within the
+// specified distance of the query item, as for typed.Tracer.CountWithin.
+func (t *Tracer) CountWithin(query interface{}, radius float64) (count int, err error) {
+	defer t.recordMetrics()
+	return t.tracer.CountWithin(query, radius)
 }
 
-// Insert inserts the specified item into the tree.
-func (t *Tracer) Insert(item interface{}) (err error) {
-	t.doWithTrace(func() {
-		err = t.tree.insertWithTrace(item, t)
-	})
-	return
+// FindFurthest returns the furthest items in the tree from the specified query
+// item, as for typed.Tracer.FindFurthest.
+func (t *Tracer) FindFurthest(query interface{}, maxResults int, minDistance float64) (results []ItemWithDistance, err error) {
+	defer t.recordMetrics()
+	return t.tracer.FindFurthest(query, maxResults, minDistance)
 }
 
-// Remove removes the given item from the tree. If no such item exists in the
-// tree, this has no effect.
-//
-// removed will be the item that was successfully removed, or nil if no matching
-// item was found.
-func (t *Tracer) Remove(item interface{}) (removed interface{}, err error) {
-	t.doWithTrace(func() {
-		removed, err = t.tree.removeWithTrace(item, t)
-	})
-	return
+// FindNearest returns the nearest items in the tree to the specified query
+// item, up to the specified maximum number of results and maximum distance, as
+// for typed.Tracer.FindNearest.
+func (t *Tracer) FindNearest(query interface{}, maxResults int, maxDistance float64) (results []ItemWithDistance, err error) {
+	defer t.recordMetrics()
+	return t.tracer.FindNearest(query, maxResults, maxDistance)
 }
 
-func (t *Tracer) String() string {
-	if t == nil {
-		return "nil"
-	}
+// FindNearestApprox returns approximate nearest items in the tree to the
+// specified query item, as for typed.Tracer.FindNearestApprox.
+func (t *Tracer) FindNearestApprox(query interface{}, maxResults int, maxDistance float64, epsilon float64) (results []ItemWithDistance, err error) {
+	defer t.recordMetrics()
+	return t.tracer.FindNearestApprox(query, maxResults, maxDistance, epsilon)
+}
 
-	return fmt.Sprintf("%v, total covered set size: %d, max visible cover set size: %d, levels traversed: %d, load children count: %d, total load children time: %v", t.TotalTime, t.TotalCoveredSetSize, t.MaxCoverSetSize, t.MaxLevelsTraversed, t.LoadChildrenCount, t.TotalLoadChildrenTime)
+// FindNearestBatch returns the nearest items in the tree to each of the
+// specified query items, as for typed.Tracer.FindNearestBatch.
+func (t *Tracer) FindNearestBatch(queries []interface{}, maxResults int, maxDistance float64) (results [][]ItemWithDistance, err error) {
+	defer t.recordMetrics()
+	return t.tracer.FindNearestBatch(queries, maxResults, maxDistance)
 }
 
-func (t *Tracer) doWithTrace(f func()) {
-	var startTime time.Time
+// FindNearestContext returns the nearest items in the tree to the specified
+// query item, as for typed.Tracer.FindNearestContext.
+func (t *Tracer) FindNearestContext(ctx context.Context, query interface{}, maxResults int, maxDistance float64) (results []ItemWithDistance, err error) {
+	defer t.recordMetrics()
+	return t.tracer.FindNearestContext(ctx, query, maxResults, maxDistance)
+}
 
-	t.reset()
+// FindNearestMatching returns the nearest items in the tree to the specified
+// query item which are accepted by the match function, as for
+// typed.Tracer.FindNearestMatching.
+func (t *Tracer) FindNearestMatching(query interface{}, maxResults int, maxDistance float64, matches MatchFunc) (results []ItemWithDistance, err error) {
+	defer t.recordMetrics()
+	return t.tracer.FindNearestMatching(query, maxResults, maxDistance, matches)
+}
 
-	defer func() {
-		t.TotalTime = time.Now().Sub(startTime)
-	}()
+// FindReverseNearest returns the items in the tree which have the query item
+// amongst their nearest neighbours, as for typed.Tracer.FindReverseNearest.
+func (t *Tracer) FindReverseNearest(query interface{}, maxNeighbours int) (results []ItemWithDistance, err error) {
+	defer t.recordMetrics()
+	return t.tracer.FindReverseNearest(query, maxNeighbours)
+}
 
-	startTime = time.Now()
-	f()
+// FindWithin returns every item in the tree which is within the specified
+// distance of the query item, as for typed.Tracer.FindWithin.
+func (t *Tracer) FindWithin(query interface{}, radius float64) (results []ItemWithDistance, err error) {
+	defer t.recordMetrics()
+	return t.tracer.FindWithin(query, radius)
 }
 
-func (t *Tracer) loadChildren(parents ...interface{}) ([]LevelsWithItems, error) {
-	var startTime time.Time
+// Insert inserts the specified item into the tree, as for typed.Tracer.Insert.
+func (t *Tracer) Insert(item interface{}) (err error) {
+	defer t.recordMetrics()
+	return t.tracer.Insert(item)
+}
 
-	t.LoadChildrenCount++
+// InsertContext inserts the specified item into the tree, as for
+// typed.Tracer.InsertContext.
+func (t *Tracer) InsertContext(ctx context.Context, item interface{}) (err error) {
+	defer t.recordMetrics()
+	return t.tracer.InsertContext(ctx, item)
+}
 
-	defer func() {
-		t.TotalLoadChildrenTime += time.Now().Sub(startTime)
-	}()
+// KernelDensity returns an estimate of the density of items in the tree around
+// the query item, as for typed.Tracer.KernelDensity.
+func (t *Tracer) KernelDensity(query interface{}, kernel Kernel, bandwidth float64, tolerance float64) (density float64, err error) {
+	defer t.recordMetrics()
+	return t.tracer.KernelDensity(query, kernel, bandwidth, tolerance)
+}
 
-	startTime = time.Now()
-	return t.tree.store.LoadChildren(parents...)
+// Remove removes the given item from the tree, as for typed.Tracer.Remove.
+//
+// removed will be the item that was successfully removed, or nil if no matching
+// item was found.
+func (t *Tracer) Remove(item interface{}) (removed interface{}, err error) {
+	defer t.recordMetrics()
+	removed, _, err = t.tracer.Remove(item)
+	return
 }
 
-func (t *Tracer) recordLevel(cs coverSet) {
-	t.TotalCoveredSetSize = cs.totalItemCount
+// RemoveContext removes the given item from the tree, as for
+// typed.Tracer.RemoveContext.
+//
+// removed will be the item that was successfully removed, or nil if no matching
+// item was found.
+func (t *Tracer) RemoveContext(ctx context.Context, item interface{}) (removed interface{}, err error) {
+	defer t.recordMetrics()
+	removed, _, err = t.tracer.RemoveContext(ctx, item)
+	return
+}
 
-	size := cs.visibleItemCount
-	if size > t.MaxCoverSetSize {
-		t.MaxCoverSetSize = size
+func (t *Tracer) String() string {
+	if t == nil {
+		return "nil"
 	}
 
-	t.MaxLevelsTraversed++
+	return t.tracer.String()
 }
 
-func (t *Tracer) reset() {
-	t.TotalCoveredSetSize = 0
-	t.MaxCoverSetSize = 0
-	t.MaxLevelsTraversed = 0
-	t.LoadChildrenCount = 0
-	t.TotalLoadChildrenTime = 0
+// recordMetrics copies the metrics recorded by the typed tracer for the most
+// recent operation.
+func (t *Tracer) recordMetrics() {
+	t.TotalCoveredSetSize = t.tracer.TotalCoveredSetSize
+	t.MaxCoverSetSize = t.tracer.MaxCoverSetSize
+	t.MaxLevelsTraversed = t.tracer.MaxLevelsTraversed
+	t.LoadChildrenCount = t.tracer.LoadChildrenCount
+	t.TotalLoadChildrenTime = t.tracer.TotalLoadChildrenTime
+	t.TotalTime = t.tracer.TotalTime
 }
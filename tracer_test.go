@@ -7,7 +7,7 @@ import (
 )
 
 type slowInMemoryStore struct {
-	realStore *inMemoryStore
+	realStore *testStore
 }
 
 func (s *slowInMemoryStore) AddItem(item, parent interface{}, level int) error {
@@ -32,7 +32,7 @@ func (s *slowInMemoryStore) UpdateItem(item, parent interface{}, level int) erro
 
 func newSlowInMemoryStore() *slowInMemoryStore {
 	return &slowInMemoryStore{
-		realStore: NewInMemoryStore(distanceBetweenPoints),
+		realStore: newTestStore(),
 	}
 }
 
@@ -53,7 +53,7 @@ func TestTracer(t *testing.T) {
 			t.Fatalf("Error inserting point: %v", err)
 		}
 
-		traverseTree(tree, store.realStore, false)
+		traverseTree(store.realStore, false)
 
 		tracer := tree.NewTracer()
 
@@ -1,18 +1,27 @@
+// Package covertree provides cover trees for nearest-neighbour search and
+// clustering of items of any type, using interface{} items.
+//
+// The trees are implemented by the typed package, which provides the same
+// trees with item types checked at compile time. This package is a thin layer
+// over typed trees of interface{} items, preserving its original API.
 package covertree
 
 import (
-	"math"
+	"context"
+	"math/rand"
+
+	"github.com/mandykoh/go-covertree/typed"
 )
 
 // Tree represents a single cover tree.
 //
+// Each method of Tree behaves as the method of the same name on typed.Tree,
+// for interface{} items.
+//
 // Trees should generally not be created except via NewTreeFromStore, and then
 // only by a Store.
 type Tree struct {
-	basis           float64
-	rootLevel       int
-	distanceBetween DistanceFunc
-	store           Store
+	tree *typed.Tree[interface{}]
 }
 
 // NewTreeWithStore creates and initialises a Tree using the specified store.
@@ -26,251 +35,231 @@ type Tree struct {
 // distanceFunc is the function used by the tree to determine the distance
 // between two items.
 func NewTreeWithStore(store Store, basis float64, rootDistance float64, distanceFunc DistanceFunc) (*Tree, error) {
-	tree := &Tree{
-		basis:           basis,
-		distanceBetween: distanceFunc,
-		store:           store,
+	tree, err := typed.NewTreeWithStore(typedStoreFor(store), basis, rootDistance, distanceFunc)
+	if err != nil {
+		return nil, err
 	}
-	tree.rootLevel = tree.levelForDistance(rootDistance)
 
-	return tree, nil
+	return &Tree{tree: tree}, nil
 }
 
-// FindNearest returns the nearest items in the tree to the specified query
-// item, up to the specified maximum number of results and maximum distance.
-//
-// Results are returned with their distances from the query item, in order from
-// closest to furthest.
-//
-// If no items are found matching the given criteria, an empty result set is
-// returned.
-//
-// Multiple calls to FindNearest and Insert are safe to make concurrently.
-func (t *Tree) FindNearest(query interface{}, maxResults int, maxDistance float64) (results []ItemWithDistance, err error) {
-	return t.findNearestWithTrace(query, maxResults, maxDistance, t.NewTracer())
-}
+// NewTreeFromItems creates a Tree using the specified store, and populates it
+// with the specified items, as for typed.NewTreeFromItems.
+func NewTreeFromItems(store Store, basis float64, items []interface{}, distanceFunc DistanceFunc) (*Tree, error) {
+	tree, err := typed.NewTreeFromItems(typedStoreFor(store), basis, items, distanceFunc)
+	if err != nil {
+		return nil, err
+	}
 
-// Insert inserts the specified item into the tree.
-//
-// Multiple calls to FindNearest and Insert are safe to make concurrently.
-func (t *Tree) Insert(item interface{}) (err error) {
-	return t.insertWithTrace(item, t.NewTracer())
+	return &Tree{tree: tree}, nil
 }
 
-// NewTracer returns a new Tracer for recording performance metrics for
-// operations on this tree.
-//
-// Tracers are not thread safe and should not be shared by multiple Goroutines.
-func (t *Tree) NewTracer() *Tracer {
-	return &Tracer{tree: t}
+// AllNearestNeighbours finds the nearest neighbours of every item in the tree,
+// as for typed.Tree.AllNearestNeighbours.
+func (t *Tree) AllNearestNeighbours(maxNeighbours int, maxDistance float64, found func(item interface{}, neighbours []ItemWithDistance) error) error {
+	return t.tree.AllNearestNeighbours(maxNeighbours, maxDistance, found)
 }
 
-// Remove removes the given item from the tree. If no such item exists in the
-// tree, this has no effect.
-//
-// removed will be the item that was successfully removed, or nil if no matching
-// item was found.
-//
-// This method is not safe for concurrent use. Calls to Remove should be
-// externally synchronised so they do not execute concurrently with each other
-// or with calls to FindNearest or Insert.
-func (t *Tree) Remove(item interface{}) (removed interface{}, err error) {
-	return t.removeWithTrace(item, t.NewTracer())
+// Analyze estimates the shape of the data in the tree, as for
+// typed.Tree.Analyze.
+func (t *Tree) Analyze() (analysis Analysis, err error) {
+	return t.tree.Analyze()
 }
 
-func (t *Tree) adoptOrphans(orphans []interface{}, query interface{}, parents coverSet, distThreshold float64, childLevel int) ([]interface{}, error) {
-	remaining := 0
-
-nextOrphan:
-	for _, item := range orphans {
-		for _, layer := range parents.layers {
-			for _, parent := range layer {
-				if parent.withDistance.Item != query && t.distanceBetween(item, parent.withDistance.Item) <= distThreshold {
-
-					err := t.store.UpdateItem(item, parent.withDistance.Item, childLevel)
-					if err != nil {
-						return nil, err
-					}
-
-					continue nextOrphan
-				}
-			}
-		}
-
-		orphans[remaining] = item
-		remaining++
-	}
-
-	return orphans[:remaining], nil
+// Clusters groups the items in the tree by the items which cover them at the
+// specified level, as for typed.Tree.Clusters.
+func (t *Tree) Clusters(level int) (clusters []Cluster, err error) {
+	return t.tree.Clusters(level)
 }
 
-func (t *Tree) distanceForLevel(level int) float64 {
-	return math.Pow(t.basis, float64(level))
+// CountWithin returns the number of items in the tree which are within the
+// specified distance of the query item, as for typed.Tree.CountWithin.
+func (t *Tree) CountWithin(query interface{}, radius float64) (count int, err error) {
+	return t.tree.CountWithin(query, radius)
 }
 
-func (t *Tree) findNearestWithTrace(query interface{}, maxResults int, maxDistance float64, tracer *Tracer) (results []ItemWithDistance, err error) {
-	cs, err := t.loadRootCoverSet(query, tracer)
-	if err != nil {
-		return nil, err
-	}
-
-	tracer.recordLevel(cs)
-
-	for level := t.rootLevel; !cs.atBottom(); level-- {
-		distThreshold := t.distanceForLevel(level) + cs.bound(maxResults, maxDistance)
-
-		cs, _, err = cs.child(query, distThreshold, level-1, t.distanceBetween, tracer.loadChildren)
-		if err != nil {
-			return
-		}
-
-		tracer.recordLevel(cs)
-	}
-
-	return cs.closest(maxResults, maxDistance), nil
+// DuplicateGroups finds groups of items in the tree which are within the
+// specified threshold distance of each other, as for
+// typed.Tree.DuplicateGroups.
+func (t *Tree) DuplicateGroups(threshold float64, found func(group []interface{}) error) error {
+	return t.tree.DuplicateGroups(threshold, found)
 }
 
-func (t *Tree) hoistRootForChild(child interface{}, minChildLevel int, root interface{}, rootLevel int) (newRootLevel, newChildLevel int) {
-	dist := t.distanceBetween(root, child)
-	childLevel := t.levelForDistance(dist)
-	newRootLevel = rootLevel
-
-	if childLevel < minChildLevel {
-		childLevel = minChildLevel
-	}
-	if childLevel >= newRootLevel {
-		newRootLevel = childLevel + 1
-	}
+// FindFurthest returns the furthest items in the tree from the specified query
+// item, as for typed.Tree.FindFurthest.
+func (t *Tree) FindFurthest(query interface{}, maxResults int, minDistance float64) (results []ItemWithDistance, err error) {
+	return t.tree.FindFurthest(query, maxResults, minDistance)
+}
 
-	return newRootLevel, childLevel
+// FindNearest returns the nearest items in the tree to the specified query
+// item, up to the specified maximum number of results and maximum distance, as
+// for typed.Tree.FindNearest.
+func (t *Tree) FindNearest(query interface{}, maxResults int, maxDistance float64) (results []ItemWithDistance, err error) {
+	return t.tree.FindNearest(query, maxResults, maxDistance)
 }
 
-func (t *Tree) insert(item interface{}, coverSet coverSet, level int, tracer *Tracer) (inserted interface{}, err error) {
-	distThreshold := t.distanceForLevel(level)
+// FindNearestApprox returns approximate nearest items in the tree to the
+// specified query item, as for typed.Tree.FindNearestApprox.
+func (t *Tree) FindNearestApprox(query interface{}, maxResults int, maxDistance float64, epsilon float64) (results []ItemWithDistance, err error) {
+	return t.tree.FindNearestApprox(query, maxResults, maxDistance, epsilon)
+}
 
-	childCoverSet, parentWithinThreshold, err := coverSet.child(item, distThreshold, level-1, t.distanceBetween, tracer.loadChildren)
-	if err != nil || childCoverSet.visibleItemCount == 0 {
-		return nil, err
-	}
+// FindNearestBatch returns the nearest items in the tree to each of the
+// specified query items, as for typed.Tree.FindNearestBatch.
+func (t *Tree) FindNearestBatch(queries []interface{}, maxResults int, maxDistance float64) (results [][]ItemWithDistance, err error) {
+	return t.tree.FindNearestBatch(queries, maxResults, maxDistance)
+}
 
-	tracer.recordLevel(childCoverSet)
+// FindNearestContext returns the nearest items in the tree to the specified
+// query item, as for typed.Tree.FindNearestContext.
+func (t *Tree) FindNearestContext(ctx context.Context, query interface{}, maxResults int, maxDistance float64) (results []ItemWithDistance, err error) {
+	return t.tree.FindNearestContext(ctx, query, maxResults, maxDistance)
+}
 
-	// A matching child which is at zero distance - item is a duplicate so insert it as a sibling
-	if layer := childCoverSet.layers[len(childCoverSet.layers)-1]; len(layer) > 0 {
-		if layer[0].withDistance.Distance == 0 {
+// FindNearestMatching returns the nearest items in the tree to the specified
+// query item which are accepted by the match function, as for
+// typed.Tree.FindNearestMatching.
+func (t *Tree) FindNearestMatching(query interface{}, maxResults int, maxDistance float64, matches MatchFunc) (results []ItemWithDistance, err error) {
+	return t.tree.FindNearestMatching(query, maxResults, maxDistance, matches)
+}
 
-			if layer[0].parent == nil {
-				err = t.store.AddItem(item, nil, level)
-			} else {
-				err = t.store.AddItem(item, layer[0].parent, level-1)
-			}
-			return item, err
-		}
-	}
+// FindReverseNearest returns the items in the tree which have the query item
+// amongst their nearest neighbours, as for typed.Tree.FindReverseNearest.
+func (t *Tree) FindReverseNearest(query interface{}, maxNeighbours int) (results []ItemWithDistance, err error) {
+	return t.tree.FindReverseNearest(query, maxNeighbours)
+}
 
-	// Look for a suitable parent amongst the children
-	inserted, err = t.insert(item, childCoverSet, level-1, tracer)
-	if inserted != nil || err != nil {
-		return
-	}
+// FindWithin returns every item in the tree which is within the specified
+// distance of the query item, as for typed.Tree.FindWithin.
+func (t *Tree) FindWithin(query interface{}, radius float64) (results []ItemWithDistance, err error) {
+	return t.tree.FindWithin(query, radius)
+}
 
-	// No parent was found among the children - pick arbitrary suitable parent at this level
-	if parentWithinThreshold != nil {
-		err = t.store.AddItem(item, parentWithinThreshold, level-1)
-		return item, err
-	}
+// Insert inserts the specified item into the tree, as for typed.Tree.Insert.
+func (t *Tree) Insert(item interface{}) (err error) {
+	return t.tree.Insert(item)
+}
 
-	return nil, nil
+// InsertAll inserts the specified items into the tree using concurrent
+// workers, as for typed.Tree.InsertAll.
+func (t *Tree) InsertAll(items []interface{}, workers int, progress ProgressFunc) error {
+	return t.tree.InsertAll(items, workers, progress)
 }
 
-func (t *Tree) insertWithTrace(item interface{}, tracer *Tracer) error {
-	cs, err := t.loadRootCoverSet(item, tracer)
-	if err != nil {
-		return err
-	}
+// InsertContext inserts the specified item into the tree, as for
+// typed.Tree.InsertContext.
+func (t *Tree) InsertContext(ctx context.Context, item interface{}) (err error) {
+	return t.tree.InsertContext(ctx, item)
+}
 
-	tracer.recordLevel(cs)
+// InsertFrom inserts the items received from the specified channel into the
+// tree using concurrent workers, as for typed.Tree.InsertFrom.
+func (t *Tree) InsertFrom(items <-chan interface{}, workers int, progress ProgressFunc) error {
+	return t.tree.InsertFrom(items, workers, progress)
+}
 
-	var inserted interface{}
-	inserted, err = t.insert(item, cs, t.rootLevel, tracer)
-	if err == nil && inserted == nil {
-		return t.store.AddItem(item, nil, t.rootLevel)
-	}
+// KDistance returns the distance from the query item to its kth nearest
+// neighbour in the tree, as for typed.Tree.KDistance.
+func (t *Tree) KDistance(query interface{}, k int) (float64, error) {
+	return t.tree.KDistance(query, k)
+}
 
-	return err
+// KDistances finds the distance from every item in the tree to its kth nearest
+// neighbour, as for typed.Tree.KDistances.
+func (t *Tree) KDistances(k int, found func(item interface{}, kDistance float64) error) error {
+	return t.tree.KDistances(k, found)
 }
 
-func (t *Tree) levelForDistance(distance float64) int {
-	return int(math.Ceil(math.Log2(distance) / math.Log2(t.basis)))
+// KernelDensity returns an estimate of the density of items in the tree around
+// the query item, as for typed.Tree.KernelDensity.
+func (t *Tree) KernelDensity(query interface{}, kernel Kernel, bandwidth float64, tolerance float64) (density float64, err error) {
+	return t.tree.KernelDensity(query, kernel, bandwidth, tolerance)
 }
 
-func (t *Tree) loadRootCoverSet(query interface{}, tracer *Tracer) (coverSet, error) {
-	roots, err := tracer.loadChildren(nil)
-	if err != nil {
-		return coverSet{}, err
-	}
+// LocalOutlierFactor returns the local outlier factor of the query item, as
+// for typed.Tree.LocalOutlierFactor.
+func (t *Tree) LocalOutlierFactor(query interface{}, k int) (float64, error) {
+	return t.tree.LocalOutlierFactor(query, k)
+}
 
-	return coverSetWithItems(roots[0].itemsAt(t.rootLevel), nil, query, t.distanceBetween, tracer.loadChildren)
+// LocalOutlierFactors finds the local outlier factor of every item in the
+// tree, as for typed.Tree.LocalOutlierFactors.
+func (t *Tree) LocalOutlierFactors(k int, found func(item interface{}, factor float64) error) error {
+	return t.tree.LocalOutlierFactors(k, found)
 }
 
-func (t *Tree) remove(item interface{}, coverSet coverSet, level int, tracer *Tracer) (removed interface{}, orphans []interface{}, err error) {
-	for _, layer := range coverSet.layers {
-		for i := range layer {
-			if layer[i].withDistance.Distance == 0 {
-				err = t.store.RemoveItem(layer[i].withDistance.Item, layer[i].parent, level)
-				if err != nil {
-					return
-				}
-				removed = layer[i].withDistance.Item
+// MinimumSpanningTree returns the edges of a minimum spanning tree of the items
+// in the tree, as for typed.Tree.MinimumSpanningTree.
+func (t *Tree) MinimumSpanningTree() ([]Edge, error) {
+	return t.tree.MinimumSpanningTree()
+}
 
-				for _, child := range layer[i].children.items {
-					orphans = append(orphans, child...)
-				}
+// Nearest returns an iterator over the items in the tree in order of
+// increasing distance from the query item, as for typed.Tree.Nearest.
+func (t *Tree) Nearest(query interface{}) *NearestIterator {
+	return t.tree.Nearest(query)
+}
 
-				// Try to get orphans adopted by one of the siblings of the deleted node
-				orphans, err = t.adoptOrphans(orphans, item, coverSet, t.distanceForLevel(level-1), level-1)
+// NewTracer returns a new Tracer for recording performance metrics for
+// operations on this tree.
+//
+// Tracers are not thread safe and should not be shared by multiple Goroutines.
+func (t *Tree) NewTracer() *Tracer {
+	return &Tracer{tracer: t.tree.NewTracer()}
+}
 
-				break
-			}
-		}
-	}
+// Remove removes the given item from the tree, as for typed.Tree.Remove.
+//
+// removed will be the item that was successfully removed, or nil if no matching
+// item was found.
+func (t *Tree) Remove(item interface{}) (removed interface{}, err error) {
+	removed, _, err = t.tree.Remove(item)
+	return
+}
 
-	if removed == nil {
-		if coverSet.atBottom() {
-			return
-		}
+// RemoveContext removes the given item from the tree, as for
+// typed.Tree.RemoveContext.
+//
+// removed will be the item that was successfully removed, or nil if no matching
+// item was found.
+func (t *Tree) RemoveContext(ctx context.Context, item interface{}) (removed interface{}, err error) {
+	removed, _, err = t.tree.RemoveContext(ctx, item)
+	return
+}
 
-		distThreshold := t.distanceForLevel(level)
-		childCoverSet, _, err := coverSet.child(item, distThreshold, level-1, t.distanceBetween, tracer.loadChildren)
-		if err != nil {
-			return nil, nil, err
-		}
-		tracer.recordLevel(childCoverSet)
+// RootDistance returns the minimum expected distance between root nodes of the
+// tree, as for typed.Tree.RootDistance.
+func (t *Tree) RootDistance() float64 {
+	return t.tree.RootDistance()
+}
 
-		removed, orphans, err = t.remove(item, childCoverSet, level-1, tracer)
-	}
+// Sample returns up to n items drawn uniformly at random from the tree, as for
+// typed.Tree.Sample.
+func (t *Tree) Sample(n int, rng *rand.Rand) (items []interface{}, err error) {
+	return t.tree.Sample(n, rng)
+}
 
-	return
+// ClosestPairs finds the closest pairs of items, one from the left tree and one
+// from the right tree, as for typed.ClosestPairs.
+func ClosestPairs(left, right *Tree, maxPairs int) (pairs []Pair, err error) {
+	return typed.ClosestPairs(left.tree, right.tree, maxPairs)
 }
 
-func (t *Tree) removeWithTrace(item interface{}, tracer *Tracer) (removed interface{}, err error) {
-	cs, err := t.loadRootCoverSet(item, tracer)
-	if err != nil {
-		return nil, err
-	}
+// DBSCAN performs density-based spatial clustering of the items in the
+// specified tree, as for typed.DBSCAN.
+func DBSCAN(tree *Tree, eps float64, minPoints int, progress func(processed, total int)) (result DBSCANResult, err error) {
+	return typed.DBSCAN(tree.tree, eps, minPoints, progress)
+}
 
-	tracer.recordLevel(cs)
-
-	var orphans []interface{}
-	removed, orphans, err = t.remove(item, cs, t.rootLevel, tracer)
-	if err == nil {
-		for _, orphan := range orphans {
-			err = t.store.UpdateItem(orphan, nil, t.rootLevel)
-			if err != nil {
-				return nil, err
-			}
-		}
-	}
+// JoinWithin finds every pair of items, one from the left tree and one from the
+// right tree, which are within the specified distance of each other, as for
+// typed.JoinWithin.
+func JoinWithin(left, right *Tree, radius float64, found func(leftItem, rightItem interface{}, distance float64) error) error {
+	return typed.JoinWithin(left.tree, right.tree, radius, found)
+}
 
-	return
+// NewDendrogram creates a single-linkage Dendrogram from the edges of a
+// minimum spanning tree, as for typed.NewDendrogram.
+func NewDendrogram(edges []Edge) *Dendrogram {
+	return typed.NewDendrogram(edges)
 }
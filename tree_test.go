@@ -1,6 +1,7 @@
 package covertree
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/rand"
@@ -124,7 +125,7 @@ func TestTree(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Expected search to succeed but got error: %v", err)
 			}
-			expectSameResults(t, query, results, []ItemWithDistance{{&p, distanceBetweenPoints(&p, &query)}})
+			expectSameResults(t, query, results, []ItemWithDistance{{Item: &p, Distance: distanceBetweenPoints(&p, &query)}})
 		})
 
 		t.Run("with a populated tree", func(t *testing.T) {
@@ -145,9 +146,9 @@ func TestTree(t *testing.T) {
 					t.Fatalf("Expected search to succeed but got error: %v", err)
 				}
 				expectSameResults(t, query, results, []ItemWithDistance{
-					{&points[0], distanceBetweenPoints(&points[0], &query)},
-					{&points[1], distanceBetweenPoints(&points[1], &query)},
-					{&points[2], distanceBetweenPoints(&points[2], &query)},
+					{Item: &points[0], Distance: distanceBetweenPoints(&points[0], &query)},
+					{Item: &points[1], Distance: distanceBetweenPoints(&points[1], &query)},
+					{Item: &points[2], Distance: distanceBetweenPoints(&points[2], &query)},
 				})
 			})
 
@@ -159,8 +160,8 @@ func TestTree(t *testing.T) {
 					t.Fatalf("Expected search to succeed but got error: %v", err)
 				}
 				expectSameResults(t, query, results, []ItemWithDistance{
-					{&points[0], distanceBetweenPoints(&points[0], &query)},
-					{&points[1], distanceBetweenPoints(&points[1], &query)},
+					{Item: &points[0], Distance: distanceBetweenPoints(&points[0], &query)},
+					{Item: &points[1], Distance: distanceBetweenPoints(&points[1], &query)},
 				})
 			})
 
@@ -172,18 +173,37 @@ func TestTree(t *testing.T) {
 					t.Fatalf("Expected search to succeed but got error: %v", err)
 				}
 				expectSameResults(t, query, results, []ItemWithDistance{
-					{&points[0], distanceBetweenPoints(&points[0], &query)},
-					{&points[1], distanceBetweenPoints(&points[1], &query)},
+					{Item: &points[0], Distance: distanceBetweenPoints(&points[0], &query)},
+					{Item: &points[1], Distance: distanceBetweenPoints(&points[1], &query)},
 				})
 			})
 		})
 	})
 
+	t.Run("Nearest()", func(t *testing.T) {
+
+		t.Run("returns a nil result before any items have been found", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			it := tree.Nearest(&Point{})
+
+			if actual := it.Result().Item; actual != nil {
+				t.Errorf("Expected a nil item but got %v", actual)
+			}
+			if it.Next() {
+				t.Errorf("Expected no results from an empty tree")
+			}
+			if actual := it.Result().Item; actual != nil {
+				t.Errorf("Expected a nil item but got %v", actual)
+			}
+		})
+	})
+
 	t.Run("Insert()", func(t *testing.T) {
 
 		t.Run("inserts duplicates of the root as sibling roots", func(t *testing.T) {
-			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
-			store := tree.store.(*inMemoryStore)
+			store := newTestStore()
+			tree, _ := NewTreeWithStore(store, 2, 1000.0, distanceBetweenPoints)
 
 			p1 := randomPoint()
 			err := tree.Insert(&p1)
@@ -197,14 +217,14 @@ func TestTree(t *testing.T) {
 				t.Fatalf("Error inserting point into tree: %v", err)
 			}
 
-			nodeCount := traverseTree(tree, store, false)
+			nodeCount := traverseTree(store, false)
 
 			if expected, actual := 2, nodeCount; expected != actual {
 				t.Errorf("Expected %d nodes in tree after inserting duplicate but found %d", expected, actual)
 			}
 
 			levels := store.levelsFor(nil)
-			if expected, actual := 2, len(levels[tree.rootLevel]); expected != actual {
+			if expected, actual := 2, len(levels[store.rootLevel()]); expected != actual {
 				t.Errorf("Expected %d roots but got %d", expected, actual)
 			}
 
@@ -225,8 +245,8 @@ func TestTree(t *testing.T) {
 		})
 
 		t.Run("inserts duplicates as siblings of the original item", func(t *testing.T) {
-			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
-			store := tree.store.(*inMemoryStore)
+			store := newTestStore()
+			tree, _ := NewTreeWithStore(store, 2, 1000.0, distanceBetweenPoints)
 
 			_, _ = insertPoints(randomPoints(2), tree)
 
@@ -242,7 +262,7 @@ func TestTree(t *testing.T) {
 				t.Fatalf("Error inserting point into tree: %v", err)
 			}
 
-			nodeCount := traverseTree(tree, store, false)
+			nodeCount := traverseTree(store, false)
 
 			if expected, actual := 4, nodeCount; expected != actual {
 				t.Errorf("Expected %d nodes in tree after inserting duplicate but found %d", expected, actual)
@@ -250,17 +270,9 @@ func TestTree(t *testing.T) {
 
 			// Duplicate items should not be children of each other (they should
 			// have no children as they were inserted last).
-			children, err := store.LoadChildren(&p1, &p2)
-			if err != nil {
-				t.Fatalf("Expected to be able to load children but got error: %v", err)
-			}
-			if expected, actual := 2, len(children); expected != actual {
-				t.Errorf("Expected %d entries for children but got %d", expected, actual)
-			} else {
-				for i := range children {
-					if expected, actual := 0, len(children[i].items); expected != actual {
-						t.Errorf("Expected no children for item %d but found %d", i, actual)
-					}
+			for i, p := range []*Point{&p1, &p2} {
+				if expected, actual := 0, len(store.levelsFor(p)); expected != actual {
+					t.Errorf("Expected no children for item %d but found %d", i, actual)
 				}
 			}
 
@@ -281,7 +293,7 @@ func TestTree(t *testing.T) {
 		})
 
 		t.Run("saves the tree root state when it changes", func(t *testing.T) {
-			store := newTestStore(distanceBetweenPoints)
+			store := newTestStore()
 			tree, _ := NewTreeWithStore(store, 2, 10.0, distanceBetweenPoints)
 
 			// First point should become the initial root at the level for the specified rootDistance
@@ -291,7 +303,7 @@ func TestTree(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Expected insert to succeed but got error: %v", err)
 			}
-			store.expectSavedTree(t, 1, []interface{}{p1}, tree.rootLevel)
+			store.expectSavedTree(t, 1, []interface{}{p1}, store.rootLevel())
 
 			// Second point should be inserted as a child
 			p2 := &Point{2.0, 0.0, 0.0}
@@ -300,7 +312,7 @@ func TestTree(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Expected insert to succeed but got error: %v", err)
 			}
-			store.expectSavedTree(t, 2, []interface{}{p1}, tree.rootLevel)
+			store.expectSavedTree(t, 2, []interface{}{p1}, store.rootLevel())
 
 			// Third point is very different and should cause a second root to be added
 			p3 := &Point{100.0, 0.0, 0.0}
@@ -390,7 +402,8 @@ func TestTree(t *testing.T) {
 		})
 
 		t.Run("removes item from the tree while preserving its children", func(t *testing.T) {
-			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			store := newTestStore()
+			tree, _ := NewTreeWithStore(store, 2, 1000.0, distanceBetweenPoints)
 
 			points := []Point{
 				{1.0, 0.0, 0.0},
@@ -406,7 +419,7 @@ func TestTree(t *testing.T) {
 				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
 			}
 
-			nodeCount := traverseTree(tree, tree.store.(*inMemoryStore), false)
+			nodeCount := traverseTree(store, false)
 			if expected, actual := len(points)-1, nodeCount; expected != actual {
 				t.Errorf("Expected %d nodes remaining after removal but found %d", expected, actual)
 			}
@@ -417,7 +430,7 @@ func TestTree(t *testing.T) {
 
 			// Orphaned child node should have been re-parented and still be findable
 			results, _ = tree.FindNearest(&points[3], 1, 0)
-			expectSameResults(t, points[3], results, []ItemWithDistance{{&points[3], 0}})
+			expectSameResults(t, points[3], results, []ItemWithDistance{{Item: &points[3], Distance: 0}})
 
 			removed, _ = tree.Remove(&points[1])
 
@@ -425,7 +438,7 @@ func TestTree(t *testing.T) {
 				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
 			}
 
-			nodeCount = traverseTree(tree, tree.store.(*inMemoryStore), false)
+			nodeCount = traverseTree(store, false)
 			if expected, actual := len(points)-2, nodeCount; expected != actual {
 				t.Errorf("Expected %d nodes remaining after removal but found %d", expected, actual)
 			}
@@ -436,7 +449,7 @@ func TestTree(t *testing.T) {
 
 			// Orphaned child node should have been re-parented and still be findable
 			results, _ = tree.FindNearest(&points[3], 1, 0)
-			expectSameResults(t, points[3], results, []ItemWithDistance{{&points[3], 0}})
+			expectSameResults(t, points[3], results, []ItemWithDistance{{Item: &points[3], Distance: 0}})
 
 			removed, _ = tree.Remove(&points[0])
 
@@ -444,7 +457,7 @@ func TestTree(t *testing.T) {
 				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
 			}
 
-			nodeCount = traverseTree(tree, tree.store.(*inMemoryStore), false)
+			nodeCount = traverseTree(store, false)
 			if expected, actual := len(points)-3, nodeCount; expected != actual {
 				t.Errorf("Expected %d nodes remaining after removal but found %d", expected, actual)
 			}
@@ -455,7 +468,7 @@ func TestTree(t *testing.T) {
 
 			// Orphaned child node should have been re-parented and still be findable
 			results, _ = tree.FindNearest(&points[3], 1, 0)
-			expectSameResults(t, points[3], results, []ItemWithDistance{{&points[3], 0}})
+			expectSameResults(t, points[3], results, []ItemWithDistance{{Item: &points[3], Distance: 0}})
 
 			removed, _ = tree.Remove(&points[3])
 
@@ -463,7 +476,7 @@ func TestTree(t *testing.T) {
 				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
 			}
 
-			nodeCount = traverseTree(tree, tree.store.(*inMemoryStore), false)
+			nodeCount = traverseTree(store, false)
 			if expected, actual := len(points)-4, nodeCount; expected != actual {
 				t.Errorf("Expected %d nodes remaining after removal but found %d", expected, actual)
 			}
@@ -474,7 +487,8 @@ func TestTree(t *testing.T) {
 		})
 
 		t.Run("correctly re-parents orphans that are no longer covered by the root", func(t *testing.T) {
-			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			store := newTestStore()
+			tree, _ := NewTreeWithStore(store, 2, 1000.0, distanceBetweenPoints)
 
 			points := []Point{
 				{0.0, 0.0, 0.0},
@@ -483,7 +497,7 @@ func TestTree(t *testing.T) {
 			}
 			_, _ = insertPoints(points, tree)
 
-			root, rootLevel, _ := loadRoot(tree)
+			root, rootLevel := loadRoot(store)
 
 			if expected, actual := &points[0], root; expected != actual {
 				t.Errorf("Expected root node to be %v before removal but was %v", expected, actual)
@@ -498,9 +512,9 @@ func TestTree(t *testing.T) {
 				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
 			}
 
-			root, rootLevel, _ = loadRoot(tree)
+			root, rootLevel = loadRoot(store)
 
-			nodeCount := traverseTree(tree, tree.store.(*inMemoryStore), false)
+			nodeCount := traverseTree(store, false)
 			if expected, actual := len(points)-1, nodeCount; expected != actual {
 				t.Fatalf("Expected %d nodes remaining after removal but found %d", expected, actual)
 			}
@@ -518,13 +532,13 @@ func TestTree(t *testing.T) {
 
 			// Remaining nodes should still be findable
 			results, _ = tree.FindNearest(&points[0], 1, 0)
-			expectSameResults(t, points[0], results, []ItemWithDistance{{&points[0], 0}})
+			expectSameResults(t, points[0], results, []ItemWithDistance{{Item: &points[0], Distance: 0}})
 			results, _ = tree.FindNearest(&points[2], 1, 0)
-			expectSameResults(t, points[2], results, []ItemWithDistance{{&points[2], 0}})
+			expectSameResults(t, points[2], results, []ItemWithDistance{{Item: &points[2], Distance: 0}})
 		})
 
 		t.Run("saves the tree root state when it changes", func(t *testing.T) {
-			store := newTestStore(distanceBetweenPoints)
+			store := newTestStore()
 			tree, _ := NewTreeWithStore(store, 2, 1000.0, distanceBetweenPoints)
 
 			points := []Point{
@@ -588,7 +602,8 @@ func TestTree(t *testing.T) {
 
 		t.Run("allows all remaining nodes to be findable after removal", func(t *testing.T) {
 			//rand.Seed(123)
-			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			store := newTestStore()
+			tree, _ := NewTreeWithStore(store, 2, 1000.0, distanceBetweenPoints)
 
 			points := randomPoints(10)
 			_, _ = insertPoints(points, tree)
@@ -608,7 +623,7 @@ func TestTree(t *testing.T) {
 					t.Errorf("Expected %v to have been removed but got %v", expected, actual)
 				}
 
-				nodeCount := traverseTree(tree, tree.store.(*inMemoryStore), false)
+				nodeCount := traverseTree(store, false)
 				if expected, actual := len(points)-i-1, nodeCount; expected != actual {
 					t.Fatalf("Expected %d nodes remaining after %d removals but found %d", expected, i+1, actual)
 				}
@@ -620,15 +635,90 @@ func TestTree(t *testing.T) {
 				// All other nodes should still be findable
 				for j := i + 1; j < len(pointsToRemove); j++ {
 					results, _ = tree.FindNearest(pointsToRemove[j], 1, 0)
-					expectSameResults(t, *pointsToRemove[j].(*Point), results, []ItemWithDistance{{pointsToRemove[j], 0}})
+					expectSameResults(t, *pointsToRemove[j].(*Point), results, []ItemWithDistance{{Item: pointsToRemove[j], Distance: 0}})
 				}
 			}
 		})
+
+		t.Run("returns the removed item", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			points := randomPoints(50)
+			_, _ = insertPoints(points, tree)
+
+			removed, err := tree.Remove(&Point{points[10][0], points[10][1], points[10][2]})
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if expected, actual := &points[10], removed; expected != actual {
+				t.Errorf("Expected %v to be removed but got %v", expected, actual)
+			}
+		})
+
+		t.Run("returns nil when no item was removed", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			_, _ = insertPoints(randomPoints(50), tree)
+
+			removed, err := tree.Remove(&Point{-1, -1, -1})
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if removed != nil {
+				t.Errorf("Expected no item to be removed but got %v", removed)
+			}
+		})
+
+		t.Run("returns the removed item when traced", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			points := randomPoints(50)
+			_, _ = insertPoints(points, tree)
+			tracer := tree.NewTracer()
+
+			removed, err := tracer.RemoveContext(context.Background(), &points[5])
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if expected, actual := &points[5], removed; expected != actual {
+				t.Errorf("Expected %v to be removed but got %v", expected, actual)
+			}
+			if tracer.LoadChildrenCount == 0 {
+				t.Errorf("Expected the removal to be traced")
+			}
+		})
+
+		t.Run("returns the removed item from a composite tree", func(t *testing.T) {
+			tree1 := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			tree2 := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			points := randomPoints(20)
+			_, _ = insertPoints(points[:10], tree1)
+			_, _ = insertPoints(points[10:], tree2)
+			composite := NewCompositeTree(tree1, tree2)
+
+			removed, err := composite.Remove(&points[15])
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if expected, actual := &points[15], removed; expected != actual {
+				t.Errorf("Expected %v to be removed but got %v", expected, actual)
+			}
+
+			removed, err = composite.Remove(&points[15])
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if removed != nil {
+				t.Errorf("Expected no item to be removed but got %v", removed)
+			}
+		})
 	})
 
 	t.Run("with randomly populated tree", func(t *testing.T) {
 		distanceCalls := 0
-		store := NewInMemoryStore(distanceBetweenPoints)
+		store := newTestStore()
 		tree, _ := NewTreeWithStore(store, 2, 1000.0, distanceBetweenPointsWithCounter(&distanceCalls))
 
 		points := randomPoints(1000)
@@ -639,7 +729,7 @@ func TestTree(t *testing.T) {
 		}
 		fmt.Printf("Building tree took %d distance calls, %dms\n", distanceCalls, timeTaken/time.Millisecond)
 
-		nodeCount := traverseTree(tree, store, false)
+		nodeCount := traverseTree(store, false)
 		fmt.Printf("Found %d nodes in tree\n", nodeCount)
 		if expected := len(points); nodeCount != expected {
 			t.Fatalf("Expected %d nodes in tree but found %d", expected, nodeCount)
@@ -650,7 +740,7 @@ func TestTree(t *testing.T) {
 			t.Run("can find all nodes individually", func(t *testing.T) {
 				for i := range points {
 					results, _ := tree.FindNearest(&points[i], 1, 0)
-					expectSameResults(t, points[i], results, []ItemWithDistance{{&points[i], 0}})
+					expectSameResults(t, points[i], results, []ItemWithDistance{{Item: &points[i], Distance: 0}})
 				}
 			})
 
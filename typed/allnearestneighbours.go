@@ -0,0 +1,207 @@
+package typed
+
+import (
+	"math"
+	"sort"
+)
+
+// AllNearestNeighbours finds the nearest items to every item in the tree, up to
+// the specified maximum number of neighbours and maximum distance per item. An
+// item is never considered to be a neighbour of itself, but other items at
+// zero distance (duplicates) are. If maxNeighbours is negative, every item
+// within the maximum distance is a neighbour.
+//
+// Rather than searching the tree separately for each item, the tree is
+// traversed against itself so that nearby items share the work of pruning
+// distant parts of the tree.
+//
+// found is called once for each item in the tree with its neighbours, in order
+// from closest to furthest. Items are reported in no particular order. If found
+// returns an error, the traversal stops and the error is returned.
+//
+// Multiple calls to AllNearestNeighbours, FindNearest and Insert are safe to
+// make concurrently.
+func (t *Tree[T]) AllNearestNeighbours(maxNeighbours int, maxDistance float64, found func(item T, neighbours []ItemWithDistance[T]) error) error {
+	loadChildren := t.NewTracer().loadChildren
+
+	roots, err := t.loadRootNodes(loadChildren)
+	if err != nil {
+		return err
+	}
+
+	return t.allNearestNeighbourNodes(roots, maxNeighbours, maxDistance, loadChildren, func(query *node[T], neighbours []nodeWithDistance[T]) error {
+		items := make([]ItemWithDistance[T], len(neighbours))
+		for i, n := range neighbours {
+			items[i] = ItemWithDistance[T]{n.node.item, n.distance}
+		}
+
+		return found(query.item, items)
+	})
+}
+
+// allNearestNeighbourNodes finds the nearest neighbours of every item beneath
+// the specified roots, as for AllNearestNeighbours, reporting each item and its
+// neighbours by their leaf nodes.
+//
+// Every item is reachable exactly once as a leaf, so leaves identify items even
+// where items cannot be compared or are equal to each other. Traversals which
+// share the same roots will also share the same leaves, as expanded nodes are
+// retained by their parents.
+func (t *Tree[T]) allNearestNeighbourNodes(roots []*node[T], maxNeighbours int, maxDistance float64, loadChildren func(...*T) ([]LevelsWithItems[T], error), found func(*node[T], []nodeWithDistance[T]) error) error {
+	for _, root := range roots {
+		err := t.allNearestNeighbours(root, t.nodesWithDistance(roots, root.item), maxNeighbours, maxDistance, loadChildren, found)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *Tree[T]) allNearestNeighbours(query *node[T], candidates []nodeWithDistance[T], maxNeighbours int, maxDistance float64, loadChildren func(...*T) ([]LevelsWithItems[T], error), found func(*node[T], []nodeWithDistance[T]) error) error {
+	queryCoverDistance := t.coverDistanceForNode(query)
+	var bound float64
+
+	// Each item is amongst its own candidates, so one more is needed to bound
+	// the distance to its neighbours
+	maxCandidates := maxNeighbours + 1
+	if maxNeighbours < 0 {
+		maxCandidates = 0
+	}
+
+	// Refine the reference candidates until none of them cover more than the
+	// query node does
+	for {
+		candidates, bound = t.boundedCandidates(candidates, queryCoverDistance, maxCandidates, maxDistance)
+
+		var toExpand []*node[T]
+		for _, c := range candidates {
+			if t.coverDistanceForNode(c.node) > queryCoverDistance {
+				toExpand = append(toExpand, c.node)
+			}
+		}
+		if len(toExpand) == 0 {
+			break
+		}
+
+		err := t.expandNodes(toExpand, loadChildren)
+		if err != nil {
+			return err
+		}
+
+		candidates = t.expandedCandidates(candidates, queryCoverDistance, bound, query.item)
+	}
+
+	if query.isLeaf() {
+		var neighbours []nodeWithDistance[T]
+		for _, c := range candidates {
+			if c.node != query && c.distance <= maxDistance {
+				neighbours = append(neighbours, c)
+			}
+		}
+
+		sort.SliceStable(neighbours, func(i, j int) bool {
+			return neighbours[i].distance < neighbours[j].distance
+		})
+		if maxNeighbours >= 0 && len(neighbours) > maxNeighbours {
+			neighbours = neighbours[:maxNeighbours]
+		}
+
+		return found(query, neighbours)
+	}
+
+	err := t.expandNodes([]*node[T]{query}, loadChildren)
+	if err != nil {
+		return err
+	}
+
+	for i, child := range query.expanded {
+		childCandidates := candidates
+		if i > 0 {
+			childCandidates = t.childCandidates(candidates, bound, query.item, child)
+		}
+
+		err = t.allNearestNeighbours(child, childCandidates, maxNeighbours, maxDistance, loadChildren, found)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// boundedCandidates returns the candidates which may contain one of the
+// nearest items to any item covered by the query, given that the query covers
+// items up to queryCoverDistance away. A maxItems of zero places no limit on
+// the number of nearest items.
+func (t *Tree[T]) boundedCandidates(candidates []nodeWithDistance[T], queryCoverDistance float64, maxItems int, maxDistance float64) (results []nodeWithDistance[T], bound float64) {
+	bound = maxDistance
+
+	if maxItems > 0 && len(candidates) >= maxItems {
+		distances := make([]float64, len(candidates))
+		for i, c := range candidates {
+			distances[i] = c.distance
+		}
+		sort.Float64s(distances)
+
+		bound = math.Min(bound, distances[maxItems-1]+queryCoverDistance)
+	}
+
+	for _, c := range candidates {
+		if c.distance-queryCoverDistance-t.coverDistanceForNode(c.node) <= bound {
+			results = append(results, c)
+		}
+	}
+
+	return results, bound
+}
+
+// childCandidates returns the candidates of a query which may contain one of
+// the nearest items to any item covered by a child of the query, with their
+// distances from the child. The triangle inequality is used to avoid computing
+// distances for candidates which can be ruled out from their distance to the
+// query alone.
+func (t *Tree[T]) childCandidates(candidates []nodeWithDistance[T], bound float64, query T, child *node[T]) []nodeWithDistance[T] {
+	childDistance := t.distanceBetween(child.item, query)
+	childCoverDistance := t.coverDistanceForNode(child)
+
+	var results []nodeWithDistance[T]
+	for _, c := range candidates {
+		if c.distance-childDistance-childCoverDistance-t.coverDistanceForNode(c.node) > bound {
+			continue
+		}
+
+		results = append(results, nodeWithDistance[T]{c.node, t.distanceBetween(c.node.item, child.item)})
+	}
+
+	return results
+}
+
+// expandedCandidates replaces candidates which cover more than the query with
+// their expanded children, omitting children which cannot contain one of the
+// nearest items according to the triangle inequality.
+func (t *Tree[T]) expandedCandidates(candidates []nodeWithDistance[T], queryCoverDistance float64, bound float64, query T) []nodeWithDistance[T] {
+	var results []nodeWithDistance[T]
+
+	for _, c := range candidates {
+		if t.coverDistanceForNode(c.node) <= queryCoverDistance {
+			results = append(results, c)
+			continue
+		}
+
+		for i, child := range c.node.expanded {
+			if i == 0 {
+				results = append(results, nodeWithDistance[T]{child, c.distance})
+				continue
+			}
+
+			if c.distance-t.parentDistanceForNode(child)-queryCoverDistance-t.coverDistanceForNode(child) > bound {
+				continue
+			}
+
+			results = append(results, nodeWithDistance[T]{child, t.distanceBetween(child.item, query)})
+		}
+	}
+
+	return results
+}
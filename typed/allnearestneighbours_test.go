@@ -0,0 +1,134 @@
+package typed
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAllNearestNeighbours(t *testing.T) {
+
+	t.Run("reports nothing for an empty tree", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		err := tree.AllNearestNeighbours(4, math.MaxFloat64, func(item *Point, neighbours []ItemWithDistance[*Point]) error {
+			t.Errorf("Expected no items to be reported but got %v", item)
+			return nil
+		})
+
+		if err != nil {
+			t.Errorf("Expected success but got error: %v", err)
+		}
+	})
+
+	t.Run("excludes items from their own neighbours but includes duplicates", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		points := []Point{
+			{1.0, 0.0, 0.0},
+			{1.0, 0.0, 0.0},
+			{5.0, 0.0, 0.0},
+		}
+		_, _ = insertPoints(points, tree)
+
+		neighboursByItem := make(map[*Point][]ItemWithDistance[*Point])
+		err := tree.AllNearestNeighbours(1, math.MaxFloat64, func(item *Point, neighbours []ItemWithDistance[*Point]) error {
+			neighboursByItem[item] = neighbours
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		expectSameResults(t, points[0], neighboursByItem[&points[0]], []ItemWithDistance[*Point]{{&points[1], 0.0}})
+		expectSameResults(t, points[1], neighboursByItem[&points[1]], []ItemWithDistance[*Point]{{&points[0], 0.0}})
+		expectSameResults(t, points[2], neighboursByItem[&points[2]], []ItemWithDistance[*Point]{{&points[0], 4.0}})
+	})
+
+	t.Run("returns the same neighbours as a linear search for every item", func(t *testing.T) {
+		cases := []struct {
+			Description   string
+			MaxNeighbours int
+			MaxDistance   float64
+		}{
+			{Description: "nearest single neighbour", MaxNeighbours: 1, MaxDistance: math.MaxFloat64},
+			{Description: "k-nearest neighbours", MaxNeighbours: 8, MaxDistance: math.MaxFloat64},
+			{Description: "k-nearest bounded distance neighbours", MaxNeighbours: 8, MaxDistance: 80},
+			{Description: "unlimited bounded distance neighbours", MaxNeighbours: -1, MaxDistance: 80},
+		}
+
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		points := randomPoints(500)
+		_, _ = insertPoints(points, tree)
+
+		for _, c := range cases {
+
+			t.Run(c.Description, func(t *testing.T) {
+				reported := 0
+
+				err := tree.AllNearestNeighbours(c.MaxNeighbours, c.MaxDistance, func(item *Point, neighbours []ItemWithDistance[*Point]) error {
+					reported++
+
+					maxResults := c.MaxNeighbours + 1
+					if c.MaxNeighbours < 0 {
+						maxResults = len(points)
+					}
+
+					query := item
+					expectedResults, _ := linearSearch(query, points, maxResults, c.MaxDistance)
+					expectSameResults(t, *query, neighbours, expectedResults[1:])
+					return nil
+				})
+
+				if err != nil {
+					t.Fatalf("Expected success but got error: %v", err)
+				}
+				if expected, actual := len(points), reported; expected != actual {
+					t.Errorf("Expected %d items to be reported but got %d", expected, actual)
+				}
+			})
+		}
+	})
+
+	t.Run("stops when the callback returns an error", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		_, _ = insertPoints(randomPoints(10), tree)
+
+		stopErr := errors.New("stop")
+		reported := 0
+
+		err := tree.AllNearestNeighbours(1, math.MaxFloat64, func(item *Point, neighbours []ItemWithDistance[*Point]) error {
+			reported++
+			return stopErr
+		})
+
+		if expected, actual := stopErr, err; expected != actual {
+			t.Errorf("Expected error %v but got %v", expected, actual)
+		}
+		if expected, actual := 1, reported; expected != actual {
+			t.Errorf("Expected %d item to be reported but got %d", expected, actual)
+		}
+	})
+
+	t.Run("distinguishes duplicates of items which cannot be compared", func(t *testing.T) {
+		tree, _ := newSliceTree([]float64{100}, []float64{10}, []float64{0}, []float64{0})
+
+		nearestDistances := make(map[float64][]float64)
+		err := tree.AllNearestNeighbours(1, math.MaxFloat64, func(item []float64, neighbours []ItemWithDistance[[]float64]) error {
+			for _, n := range neighbours {
+				nearestDistances[item[0]] = append(nearestDistances[item[0]], n.Distance)
+			}
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		for item, expected := range map[float64][]float64{0: {0, 0}, 10: {10}, 100: {90}} {
+			if actual := nearestDistances[item]; len(actual) != len(expected) || actual[0] != expected[0] {
+				t.Errorf("Expected nearest distances %v for %g but got %v", expected, item, actual)
+			}
+		}
+	})
+}
@@ -0,0 +1,246 @@
+package typed
+
+import (
+	"math"
+	"sort"
+)
+
+// analysisSampleSize is the maximum number of items used as sample points by
+// Analyze.
+const analysisSampleSize = 64
+
+// analysisHistogramBins is the number of bins in the distance histogram
+// reported by Analyze.
+const analysisHistogramBins = 16
+
+// Analysis describes the shape of the data in a Tree, as estimated by Analyze.
+//
+// ExpansionConstant estimates the factor by which the number of items within a
+// given distance of an item typically grows when the distance is doubled, or is
+// zero if the tree has too few items to tell. IntrinsicDimension estimates the
+// number of dimensions the data effectively occupies, regardless of the
+// dimensionality of the space it is embedded in.
+//
+// EstimatedDiameter is the largest distance found between any two items. Only
+// the distances from sample items are considered, so this is a lower bound on
+// the true diameter, though no less than half of it.
+//
+// DistanceHistogram counts the distances between pairs of sample items.
+//
+// RecommendedBasis and RecommendedRootDistance are suggested values for the
+// corresponding parameters of NewTreeWithStore for similar data. No root
+// distance can be recommended for fewer than two distinct items, in which case
+// it is zero.
+type Analysis struct {
+	ItemCount               int
+	ExpansionConstant       float64
+	IntrinsicDimension      float64
+	EstimatedDiameter       float64
+	DistanceHistogram       []HistogramBin
+	RecommendedBasis        float64
+	RecommendedRootDistance float64
+}
+
+// HistogramBin represents a count of distances which fall within a range. Each
+// bin includes its minimum distance but not its maximum distance, except for
+// the last bin of a histogram, which includes both.
+type HistogramBin struct {
+	MinDistance float64
+	MaxDistance float64
+	Count       int
+}
+
+// Analyze estimates the shape of the data in the tree, to help choose the
+// parameters of trees for similar data.
+//
+// The expansion constant, diameter and distance histogram are estimated from a
+// sample of up to 64 items spread throughout the tree, using CountWithin,
+// FindFurthest and the distances between the sample items respectively. The
+// counts are made at the distances of the levels of the tree which hold items,
+// and the intrinsic dimension is the base two logarithm of the expansion
+// constant. Items near the edges of the data have fewer neighbours, so both
+// tend to be underestimated.
+//
+// The recommended basis is a rough heuristic rather than an optimal value. It
+// balances the depth of the tree against the number of children of each node,
+// which grows with the basis raised to the power of the intrinsic dimension, by
+// taking e to the power of the reciprocal of the intrinsic dimension and
+// limiting the result to between 1.2 and 2. Lower dimensional data therefore
+// favours larger bases. The recommended root distance is the estimated
+// diameter, which as a lower bound may leave the tree with several roots.
+//
+// The entire structure of the tree is loaded to perform the analysis. The tree
+// should not be modified while the analysis is in progress.
+func (t *Tree[T]) Analyze() (analysis Analysis, err error) {
+	tracer := t.NewTracer()
+
+	levelCounts, items, err := t.itemsByLevel(tracer)
+	if err != nil {
+		return analysis, err
+	}
+
+	analysis.ItemCount = len(items)
+	analysis.RecommendedBasis = 2.0
+	if len(items) == 0 {
+		return analysis, nil
+	}
+
+	samples := items
+	if len(samples) > analysisSampleSize {
+		samples = make([]T, analysisSampleSize)
+		for i := range samples {
+			samples[i] = items[i*len(items)/analysisSampleSize]
+		}
+	}
+
+	levels := t.coveringLevels(levelCounts, len(items))
+	analysis.ExpansionConstant, err = t.expansionConstant(samples, levels, len(items), tracer)
+	if err != nil {
+		return analysis, err
+	}
+
+	if analysis.ExpansionConstant > 1 {
+		analysis.IntrinsicDimension = math.Log2(analysis.ExpansionConstant)
+		analysis.RecommendedBasis = math.Max(1.2, math.Min(2.0, math.Exp(1/analysis.IntrinsicDimension)))
+	}
+
+	for _, sample := range samples {
+		furthest, err := t.findFurthestWithTrace(sample, 1, 0, tracer)
+		if err != nil {
+			return analysis, err
+		}
+		analysis.EstimatedDiameter = math.Max(analysis.EstimatedDiameter, furthest[0].Distance)
+	}
+	analysis.RecommendedRootDistance = analysis.EstimatedDiameter
+
+	analysis.DistanceHistogram = t.distanceHistogram(samples)
+
+	return analysis, nil
+}
+
+// coveringLevels returns the levels of the tree, from highest to lowest, from
+// the first level at which more than one item is needed to cover the tree down
+// to the lowest level holding any items.
+func (t *Tree[T]) coveringLevels(levelCounts map[int]int, itemCount int) (levels []int) {
+	for level := range levelCounts {
+		levels = append(levels, level)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(levels)))
+
+	if len(levels) == 0 {
+		return nil
+	}
+
+	var covering []int
+	covered := 0
+	for level := levels[0]; level >= levels[len(levels)-1]; level-- {
+		covered += levelCounts[level]
+		if covered > 1 {
+			covering = append(covering, level)
+		}
+		if covered == itemCount {
+			break
+		}
+	}
+
+	return covering
+}
+
+// distanceHistogram counts the distances between every pair of the specified
+// items into evenly sized bins.
+func (t *Tree[T]) distanceHistogram(items []T) []HistogramBin {
+	var distances []float64
+	maxDistance := 0.0
+
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			d := t.distanceBetween(items[i], items[j])
+			distances = append(distances, d)
+			maxDistance = math.Max(maxDistance, d)
+		}
+	}
+
+	if len(distances) == 0 {
+		return nil
+	}
+
+	binCount := analysisHistogramBins
+	if maxDistance == 0 {
+		binCount = 1
+	}
+
+	bins := make([]HistogramBin, binCount)
+	binWidth := maxDistance / float64(binCount)
+	for i := range bins {
+		bins[i].MinDistance = float64(i) * binWidth
+		bins[i].MaxDistance = float64(i+1) * binWidth
+	}
+	bins[binCount-1].MaxDistance = maxDistance
+
+	for _, d := range distances {
+		i := binCount - 1
+		if binWidth > 0 {
+			i = int(math.Min(float64(binCount-1), math.Floor(d/binWidth)))
+		}
+		bins[i].Count++
+	}
+
+	return bins
+}
+
+// expansionConstant estimates the expansion constant as the geometric mean of
+// the growth in the number of items around each sample item when the distance
+// at each of the specified levels is doubled. Distances which only reach the
+// sample item itself, or which already reach every item, are not considered.
+func (t *Tree[T]) expansionConstant(samples []T, levels []int, itemCount int, tracer *Tracer[T]) (float64, error) {
+	logRatioSum := 0.0
+	ratioCount := 0
+
+	for _, sample := range samples {
+		for _, level := range levels {
+			radius := t.distanceForLevel(level)
+
+			inner, err := t.countWithinWithTrace(sample, radius, tracer)
+			if err != nil {
+				return 0, err
+			}
+			if inner < 2 {
+				continue
+			}
+
+			outer, err := t.countWithinWithTrace(sample, 2*radius, tracer)
+			if err != nil {
+				return 0, err
+			}
+			if outer >= itemCount {
+				continue
+			}
+
+			logRatioSum += math.Log(float64(outer) / float64(inner))
+			ratioCount++
+		}
+	}
+
+	if ratioCount == 0 {
+		return 0, nil
+	}
+
+	return math.Exp(logRatioSum / float64(ratioCount)), nil
+}
+
+// itemsByLevel loads every item in the tree, returning the items along with the
+// number of items at each level.
+func (t *Tree[T]) itemsByLevel(tracer *Tracer[T]) (levelCounts map[int]int, items []T, err error) {
+	levelCounts = make(map[int]int)
+
+	err = traverseBreadthFirst(t, tracer, true, func(item T, _ struct{}, level int) (struct{}, error) {
+		levelCounts[level]++
+		items = append(items, item)
+		return struct{}{}, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return levelCounts, items, nil
+}
@@ -0,0 +1,142 @@
+package typed
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+
+	pointsInDimensions := func(count, dimensions int) (points []Point) {
+		for i := 0; i < count; i++ {
+			var p Point
+			for d := 0; d < dimensions; d++ {
+				p[d] = rand.Float64() * 1000
+			}
+			points = append(points, p)
+		}
+		return
+	}
+
+	t.Run("reports nothing for an empty tree", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		analysis, err := tree.Analyze()
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if expected, actual := 0, analysis.ItemCount; expected != actual {
+			t.Errorf("Expected %d items but got %d", expected, actual)
+		}
+		if expected, actual := 0, len(analysis.DistanceHistogram); expected != actual {
+			t.Errorf("Expected %d histogram bins but got %d", expected, actual)
+		}
+		if expected, actual := 2.0, analysis.RecommendedBasis; expected != actual {
+			t.Errorf("Expected recommended basis of %g but got %g", expected, actual)
+		}
+	})
+
+	t.Run("estimates higher dimensions for data which occupies more dimensions", func(t *testing.T) {
+		var dimensions []float64
+
+		for _, c := range []struct {
+			Dimensions int
+			Min        float64
+			Max        float64
+		}{
+			{Dimensions: 1, Min: 0.5, Max: 1.5},
+			{Dimensions: 3, Min: 1.8, Max: 3.5},
+		} {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			_, _ = insertPoints(pointsInDimensions(2000, c.Dimensions), tree)
+
+			analysis, err := tree.Analyze()
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+
+			if analysis.IntrinsicDimension < c.Min || analysis.IntrinsicDimension > c.Max {
+				t.Errorf("Expected intrinsic dimension of %d dimensional data to be between %g and %g but got %g", c.Dimensions, c.Min, c.Max, analysis.IntrinsicDimension)
+			}
+			if expected, actual := math.Pow(2, analysis.IntrinsicDimension), analysis.ExpansionConstant; math.Abs(expected-actual) > 1e-9 {
+				t.Errorf("Expected expansion constant of %g but got %g", expected, actual)
+			}
+			dimensions = append(dimensions, analysis.IntrinsicDimension)
+		}
+
+		if dimensions[0] >= dimensions[1] {
+			t.Errorf("Expected %g to be lower than %g", dimensions[0], dimensions[1])
+		}
+	})
+
+	t.Run("with randomly populated tree", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		points := randomPoints(500)
+		_, _ = insertPoints(points, tree)
+
+		analysis, err := tree.Analyze()
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+
+		t.Run("counts every item", func(t *testing.T) {
+			if expected, actual := len(points), analysis.ItemCount; expected != actual {
+				t.Errorf("Expected %d items but got %d", expected, actual)
+			}
+		})
+
+		t.Run("estimates a diameter no larger than the true diameter", func(t *testing.T) {
+			diameter := 0.0
+			for i := range points {
+				for j := i + 1; j < len(points); j++ {
+					diameter = math.Max(diameter, distanceBetweenPoints(&points[i], &points[j]))
+				}
+			}
+
+			if analysis.EstimatedDiameter > diameter || analysis.EstimatedDiameter < diameter/2 {
+				t.Errorf("Expected diameter between %g and %g but got %g", diameter/2, diameter, analysis.EstimatedDiameter)
+			}
+			if expected, actual := analysis.EstimatedDiameter, analysis.RecommendedRootDistance; expected != actual {
+				t.Errorf("Expected recommended root distance of %g but got %g", expected, actual)
+			}
+		})
+
+		t.Run("counts distances between every pair of sample items", func(t *testing.T) {
+			total := 0
+			for i, bin := range analysis.DistanceHistogram {
+				total += bin.Count
+
+				if i > 0 {
+					if expected, actual := analysis.DistanceHistogram[i-1].MaxDistance, bin.MinDistance; expected != actual {
+						t.Errorf("Expected bin %d to start at %g but got %g", i, expected, actual)
+					}
+				}
+			}
+
+			if expected, actual := analysisSampleSize*(analysisSampleSize-1)/2, total; expected != actual {
+				t.Errorf("Expected %d distances but got %d", expected, actual)
+			}
+		})
+
+		t.Run("recommends a basis within a sensible range", func(t *testing.T) {
+			if analysis.RecommendedBasis < 1.2 || analysis.RecommendedBasis > 2.0 {
+				t.Errorf("Expected recommended basis between 1.2 and 2 but got %g", analysis.RecommendedBasis)
+			}
+		})
+	})
+
+	t.Run("returns errors from the store", func(t *testing.T) {
+		storeErr := errors.New("store failure")
+		tree, _ := NewTreeWithStore[*Point](&failingStore{err: storeErr}, 2, 1000.0, distanceBetweenPoints)
+
+		_, err := tree.Analyze()
+
+		if expected, actual := storeErr, err; expected != actual {
+			t.Errorf("Expected error %v but got %v", expected, actual)
+		}
+	})
+}
@@ -0,0 +1,183 @@
+package typed
+
+// NewTreeFromItems creates a Tree using the specified store, and populates it
+// with the specified items. This is much more efficient than inserting the
+// items individually, as no searches of the tree are necessary and every
+// distance is computed between an item and its prospective parents only.
+//
+// The tree is built from the top down, by repeatedly splitting the items
+// covered by each node amongst new children at the next level which is needed
+// to cover them. New items are added to the store one level at a time, with
+// every parent being added before its children.
+//
+// basis and distanceFunc are as for NewTreeWithStore. Rather than requiring a
+// root distance, the first item becomes the root of the tree, at the lowest
+// level at which it covers all the other items. Trees which are later created
+// from the same store should use the resulting tree’s RootDistance.
+//
+// If there are no items, or they are all at zero distance from each other, the
+// tree is created with a root distance of 1.
+//
+// The store should be empty.
+func NewTreeFromItems[T any](store Store[T], basis float64, items []T, distanceFunc DistanceFunc[T]) (*Tree[T], error) {
+	tree := &Tree[T]{
+		basis:           basis,
+		distanceBetween: distanceFunc,
+		store:           store,
+	}
+
+	if len(items) == 0 {
+		return tree, nil
+	}
+
+	root := &bulkLoadGroup[T]{item: items[0]}
+	var duplicates []T
+
+	for _, item := range items[1:] {
+		distance := distanceFunc(item, root.item)
+		if distance == 0 {
+			duplicates = append(duplicates, item)
+		} else {
+			root.add(item, distance)
+		}
+	}
+
+	if len(root.items) > 0 {
+		tree.rootLevel = tree.coveringLevel(root.maxDistance)
+	}
+
+	err := store.AddItem(root.item, nil, tree.rootLevel)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range duplicates {
+		err = store.AddItem(item, nil, tree.rootLevel)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pending := make(map[int][]*bulkLoadGroup[T])
+	tree.addBulkLoadGroup(pending, root, tree.rootLevel)
+
+	for len(pending) > 0 {
+		level := 0
+		first := true
+		for l := range pending {
+			if first || l > level {
+				level = l
+				first = false
+			}
+		}
+
+		groups := pending[level]
+		delete(pending, level)
+
+		for _, g := range groups {
+			children, err := tree.splitBulkLoadGroup(g, level)
+			if err != nil {
+				return nil, err
+			}
+
+			tree.addBulkLoadGroup(pending, g, level)
+			for _, child := range children {
+				tree.addBulkLoadGroup(pending, child, level)
+			}
+		}
+	}
+
+	return tree, nil
+}
+
+// addBulkLoadGroup schedules a group whose items are all covered at the
+// specified level to be split at the next level needed to cover its items.
+// Groups without items have nothing left to split.
+func (t *Tree[T]) addBulkLoadGroup(pending map[int][]*bulkLoadGroup[T], g *bulkLoadGroup[T], level int) {
+	if len(g.items) == 0 {
+		return
+	}
+
+	childLevel := t.coveringLevel(g.maxDistance) - 1
+	if childLevel >= level {
+		childLevel = level - 1
+	}
+
+	pending[childLevel] = append(pending[childLevel], g)
+}
+
+// coveringLevel returns the lowest level whose nodes cover the specified
+// distance.
+func (t *Tree[T]) coveringLevel(distance float64) int {
+	level := t.levelForDistance(distance)
+
+	for t.distanceForLevel(level) < distance {
+		level++
+	}
+	for t.distanceForLevel(level-1) >= distance {
+		level--
+	}
+
+	return level
+}
+
+// splitBulkLoadGroup adds the group’s items which are too far away from it to
+// be covered at the specified level as its children, at that level. Each of
+// the remaining items is either kept by the group or handed over to a group
+// for the first new child which covers it.
+func (t *Tree[T]) splitBulkLoadGroup(g *bulkLoadGroup[T], level int) (children []*bulkLoadGroup[T], err error) {
+	distThreshold := t.distanceForLevel(level)
+
+	remaining := g.items
+	g.items = nil
+	g.maxDistance = 0
+
+nextItem:
+	for _, item := range remaining {
+		if item.Distance <= distThreshold {
+			g.add(item.Item, item.Distance)
+			continue
+		}
+
+		for _, child := range children {
+			distance := t.distanceBetween(item.Item, child.item)
+			if distance > distThreshold {
+				continue
+			}
+
+			// Items at zero distance are duplicates, so they become siblings
+			if distance == 0 {
+				err = t.store.AddItem(item.Item, &g.item, level)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				child.add(item.Item, distance)
+			}
+			continue nextItem
+		}
+
+		err = t.store.AddItem(item.Item, &g.item, level)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, &bulkLoadGroup[T]{item: item.Item})
+	}
+
+	return children, nil
+}
+
+// bulkLoadGroup represents an item which has been added to a tree being bulk
+// loaded, along with the items it covers which have yet to be added beneath
+// it.
+type bulkLoadGroup[T any] struct {
+	item        T
+	items       []ItemWithDistance[T]
+	maxDistance float64
+}
+
+func (g *bulkLoadGroup[T]) add(item T, distance float64) {
+	g.items = append(g.items, ItemWithDistance[T]{item, distance})
+	if distance > g.maxDistance {
+		g.maxDistance = distance
+	}
+}
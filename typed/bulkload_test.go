@@ -0,0 +1,187 @@
+package typed
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+type failingAddStore struct {
+	inMemoryStore[*Point]
+	err error
+}
+
+func (s *failingAddStore) AddItem(item *Point, parent **Point, level int) error {
+	return s.err
+}
+
+func TestNewTreeFromItems(t *testing.T) {
+
+	itemsFor := func(points []Point) (items []*Point) {
+		for i := range points {
+			items = append(items, &points[i])
+		}
+		return items
+	}
+
+	expectCoveredChildren := func(t *testing.T, tree *Tree[*Point], store *inMemoryStore[*Point]) {
+		t.Helper()
+
+		for parent, levels := range store.items {
+			if parent == nil {
+				continue
+			}
+			for level, children := range levels {
+				for _, child := range children {
+					if distance, coverDistance := distanceBetweenPoints(child, parent.(*Point)), tree.distanceForLevel(level+1); distance > coverDistance {
+						t.Errorf("Expected child %v at level %d to be within %g of parent %v but was %g away", child, level, coverDistance, parent, distance)
+					}
+				}
+			}
+		}
+	}
+
+	t.Run("creates an empty tree from no items", func(t *testing.T) {
+		store := NewInMemoryStore(distanceBetweenPoints)
+
+		tree, err := NewTreeFromItems[*Point](store, 2, nil, distanceBetweenPoints)
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if expected, actual := 0, traverseTree(tree, store, false); expected != actual {
+			t.Errorf("Expected %d items in tree but got %d", expected, actual)
+		}
+	})
+
+	t.Run("adds every item to the store with covering parents", func(t *testing.T) {
+		points := randomPoints(1000)
+		store := newTestStore(distanceBetweenPoints)
+
+		tree, err := NewTreeFromItems[*Point](store, 2, itemsFor(points), distanceBetweenPoints)
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if expected, actual := len(points), store.savedCount; expected != actual {
+			t.Errorf("Expected %d items to be saved but got %d", expected, actual)
+		}
+		if expected, actual := len(points), traverseTree(tree, &store.inMemoryStore, false); expected != actual {
+			t.Errorf("Expected %d items in tree but got %d", expected, actual)
+		}
+		store.expectSavedTree(t, len(points), []*Point{&points[0]}, tree.rootLevel)
+		expectCoveredChildren(t, tree, &store.inMemoryStore)
+	})
+
+	t.Run("derives the root level from the spread of the items", func(t *testing.T) {
+		points := []Point{{0, 0, 0}, {3, 0, 0}, {0, 4, 0}, {5, 0, 0}}
+
+		tree, _ := NewTreeFromItems[*Point](NewInMemoryStore(distanceBetweenPoints), 2, itemsFor(points), distanceBetweenPoints)
+
+		if expected, actual := 3, tree.rootLevel; expected != actual {
+			t.Errorf("Expected root level %d but got %d", expected, actual)
+		}
+	})
+
+	t.Run("adds duplicate items as siblings", func(t *testing.T) {
+		points := []Point{{0, 0, 0}, {0, 0, 0}, {10, 0, 0}, {10, 0, 0}, {1, 0, 0}}
+		store := NewInMemoryStore(distanceBetweenPoints)
+
+		tree, err := NewTreeFromItems[*Point](store, 2, itemsFor(points), distanceBetweenPoints)
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+
+		if expected, actual := len(points), traverseTree(tree, store, false); expected != actual {
+			t.Errorf("Expected %d items in tree but got %d", expected, actual)
+		}
+		for _, query := range []Point{{0, 0, 0}, {10, 0, 0}} {
+			results, _ := tree.FindWithin(&query, 0)
+			if expected, actual := 2, len(results); expected != actual {
+				t.Errorf("Expected %d items at %v but got %d", expected, query, actual)
+			}
+		}
+	})
+
+	t.Run("uses a root distance of 1 when all items are duplicates", func(t *testing.T) {
+		points := []Point{{1, 2, 3}, {1, 2, 3}}
+
+		tree, _ := NewTreeFromItems[*Point](NewInMemoryStore(distanceBetweenPoints), 2, itemsFor(points), distanceBetweenPoints)
+
+		if expected, actual := 1.0, tree.RootDistance(); expected != actual {
+			t.Errorf("Expected root distance %g but got %g", expected, actual)
+		}
+	})
+
+	t.Run("creates a tree which returns the same results as a linear search", func(t *testing.T) {
+		distanceCalls := 0
+		points := randomPoints(1000)
+
+		tree, err := NewTreeFromItems[*Point](NewInMemoryStore(distanceBetweenPoints), 2, itemsFor(points), distanceBetweenPointsWithCounter(&distanceCalls))
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+
+		for i := 0; i < 5; i++ {
+			compareWithLinearSearch(tree, points, 5, math.MaxFloat64, &distanceCalls, t)
+		}
+	})
+
+	t.Run("creates a tree which supports further insertion", func(t *testing.T) {
+		distanceCalls := 0
+		points := randomPoints(1000)
+
+		tree, _ := NewTreeFromItems[*Point](NewInMemoryStore(distanceBetweenPoints), 2, itemsFor(points[:500]), distanceBetweenPointsWithCounter(&distanceCalls))
+		_, err := insertPoints(points[500:], tree)
+		if err != nil {
+			t.Fatalf("Error inserting point: %v", err)
+		}
+
+		compareWithLinearSearch(tree, points, 5, math.MaxFloat64, &distanceCalls, t)
+	})
+
+	t.Run("creates a tree which can be recreated from its store using its root distance", func(t *testing.T) {
+		distanceCalls := 0
+		points := randomPoints(1000)
+		store := NewInMemoryStore(distanceBetweenPoints)
+
+		for _, basis := range []float64{1.3, 1.5, 2} {
+			bulkTree, _ := NewTreeFromItems[*Point](store, basis, itemsFor(points), distanceBetweenPoints)
+
+			tree, _ := NewTreeWithStore[*Point](store, basis, bulkTree.RootDistance(), distanceBetweenPointsWithCounter(&distanceCalls))
+
+			if expected, actual := bulkTree.rootLevel, tree.rootLevel; expected != actual {
+				t.Errorf("Expected root level %d for basis %g but got %d", expected, basis, actual)
+			}
+			compareWithLinearSearch(tree, points, 5, math.MaxFloat64, &distanceCalls, t)
+
+			store = NewInMemoryStore(distanceBetweenPoints)
+		}
+	})
+
+	t.Run("requires fewer distance comparisons than inserting items individually", func(t *testing.T) {
+		points := randomPoints(1000)
+
+		bulkDistanceCalls := 0
+		_, _ = NewTreeFromItems[*Point](NewInMemoryStore(distanceBetweenPoints), 2, itemsFor(points), distanceBetweenPointsWithCounter(&bulkDistanceCalls))
+
+		insertDistanceCalls := 0
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPointsWithCounter(&insertDistanceCalls))
+		_, _ = insertPoints(points, tree)
+
+		if bulkDistanceCalls >= insertDistanceCalls {
+			t.Errorf("Expected fewer than %d distance comparisons but got %d", insertDistanceCalls, bulkDistanceCalls)
+		}
+	})
+
+	t.Run("returns errors from the store", func(t *testing.T) {
+		storeErr := errors.New("store failure")
+		points := randomPoints(10)
+
+		_, err := NewTreeFromItems[*Point](&failingAddStore{inMemoryStore: *NewInMemoryStore(distanceBetweenPoints), err: storeErr}, 2, itemsFor(points), distanceBetweenPoints)
+
+		if expected, actual := storeErr, err; expected != actual {
+			t.Errorf("Expected error %v but got %v", expected, actual)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package typed
+
+import "math"
+
+// Pair represents a pair of items, one from each of two trees, along with the
+// distance between them.
+type Pair[T any] struct {
+	Left     T
+	Right    T
+	Distance float64
+}
+
+// ClosestPairs finds the closest pairs of items, one from the left tree and one
+// from the right tree, up to the specified maximum number of pairs.
+//
+// The two trees are traversed against each other, exploring pairs of nodes in
+// order of the closest distance any of their items could possibly be from each
+// other, so that pairs of nodes which are too far apart to contain one of the
+// closest pairs are never expanded or even have their distances computed.
+//
+// Both trees must use the same DistanceFunc, and distances are computed using
+// that of the left tree.
+//
+// Pairs are returned in order from closest to furthest. If there are fewer
+// items in either tree than the maximum number of pairs, every possible pair is
+// returned.
+//
+// Multiple calls to ClosestPairs, FindNearest and Insert are safe to make
+// concurrently.
+func ClosestPairs[T any](left, right *Tree[T], maxPairs int) (pairs []Pair[T], err error) {
+	loadLeftChildren := left.NewTracer().loadChildren
+	loadRightChildren := right.NewTracer().loadChildren
+
+	leftRoots, err := left.loadRootNodes(loadLeftChildren)
+	if err != nil {
+		return nil, err
+	}
+
+	rightRoots, err := right.loadRootNodes(loadRightChildren)
+	if err != nil {
+		return nil, err
+	}
+
+	var queue priorityQueue[nodePair[T]]
+	push := func(l, r *node[T], distance float64) {
+		key := math.Max(0, distance-left.coverDistanceForNode(l)-right.coverDistanceForNode(r))
+		queue.push(nodePair[T]{l, r, distance}, key)
+	}
+
+	// Children are queued according to a bound on their distance derived from
+	// the triangle inequality, and their distance is only computed when they
+	// reach the front of the queue
+	pushBounded := func(l, r *node[T], bound float64) {
+		key := math.Max(0, bound-left.coverDistanceForNode(l)-right.coverDistanceForNode(r))
+		queue.push(nodePair[T]{l, r, -1}, key)
+	}
+
+	for _, l := range leftRoots {
+		for _, r := range rightRoots {
+			push(l, r, left.distanceBetween(l.item, r.item))
+		}
+	}
+
+	for len(pairs) < maxPairs && queue.Len() > 0 {
+		p, _ := queue.pop()
+
+		if p.distance < 0 {
+			push(p.left, p.right, left.distanceBetween(p.left.item, p.right.item))
+			continue
+		}
+
+		if p.isLeaf() {
+			pairs = append(pairs, Pair[T]{p.left.item, p.right.item, p.distance})
+			continue
+		}
+
+		// Expand whichever node covers more, as for JoinWithin
+		if p.right.isLeaf() || (!p.left.isLeaf() && left.coverDistanceForNode(p.left) >= right.coverDistanceForNode(p.right)) {
+			err = left.expandNodes([]*node[T]{p.left}, loadLeftChildren)
+			if err != nil {
+				return nil, err
+			}
+
+			push(p.left.expanded[0], p.right, p.distance)
+			for _, child := range p.left.expanded[1:] {
+				pushBounded(child, p.right, p.distance-left.parentDistanceForNode(child))
+			}
+			continue
+		}
+
+		err = right.expandNodes([]*node[T]{p.right}, loadRightChildren)
+		if err != nil {
+			return nil, err
+		}
+
+		push(p.left, p.right.expanded[0], p.distance)
+		for _, child := range p.right.expanded[1:] {
+			pushBounded(p.left, child, p.distance-right.parentDistanceForNode(child))
+		}
+	}
+
+	return pairs, nil
+}
+
+// nodePair represents a node from each of two trees, along with the distance
+// between their items, or -1 if the distance has not yet been computed.
+type nodePair[T any] struct {
+	left     *node[T]
+	right    *node[T]
+	distance float64
+}
+
+func (p nodePair[T]) isLeaf() bool {
+	return p.left.isLeaf() && p.right.isLeaf()
+}
@@ -0,0 +1,131 @@
+package typed
+
+import (
+	"errors"
+	"sort"
+	"testing"
+)
+
+func TestClosestPairs(t *testing.T) {
+
+	t.Run("returns nothing when either tree is empty", func(t *testing.T) {
+		populated := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		_, _ = insertPoints(randomPoints(10), populated)
+
+		empty := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		for _, trees := range [][2]*Tree[*Point]{{populated, empty}, {empty, populated}} {
+			pairs, err := ClosestPairs(trees[0], trees[1], 5)
+
+			if err != nil {
+				t.Errorf("Expected success but got error: %v", err)
+			}
+			if expected, actual := 0, len(pairs); expected != actual {
+				t.Errorf("Expected %d pairs but got %d", expected, actual)
+			}
+		}
+	})
+
+	t.Run("returns every pair when there are too few items", func(t *testing.T) {
+		left := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		_, _ = insertPoints(randomPoints(3), left)
+
+		right := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		_, _ = insertPoints(randomPoints(4), right)
+
+		pairs, err := ClosestPairs(left, right, 100)
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if expected, actual := 12, len(pairs); expected != actual {
+			t.Errorf("Expected %d pairs but got %d", expected, actual)
+		}
+	})
+
+	t.Run("returns the same pairs as a linear search", func(t *testing.T) {
+		distanceCalls := 0
+		distanceFunc := distanceBetweenPointsWithCounter(&distanceCalls)
+
+		left := NewInMemoryTree(2, 1000.0, distanceFunc)
+		leftPoints := randomPoints(300)
+		_, _ = insertPoints(leftPoints, left)
+
+		right := NewInMemoryTree(2, 1000.0, distanceFunc)
+		rightPoints := randomPoints(300)
+		_, _ = insertPoints(rightPoints, right)
+
+		var expectedPairs []Pair[*Point]
+		for i := range leftPoints {
+			for j := range rightPoints {
+				expectedPairs = append(expectedPairs, Pair[*Point]{&leftPoints[i], &rightPoints[j], distanceBetweenPoints(&leftPoints[i], &rightPoints[j])})
+			}
+		}
+		sort.SliceStable(expectedPairs, func(i, j int) bool {
+			return expectedPairs[i].Distance < expectedPairs[j].Distance
+		})
+
+		for _, maxPairs := range []int{1, 10, 50} {
+			distanceCalls = 0
+
+			pairs, err := ClosestPairs(left, right, maxPairs)
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if pairwise := len(leftPoints) * len(rightPoints); distanceCalls >= pairwise/10 {
+				t.Errorf("Expected fewer than %d distance calls but made %d", pairwise/10, distanceCalls)
+			}
+			if expected, actual := maxPairs, len(pairs); expected != actual {
+				t.Fatalf("Expected %d pairs but got %d", expected, actual)
+			}
+
+			for i, p := range pairs {
+				if expected, actual := expectedPairs[i].Distance, p.Distance; expected != actual {
+					t.Errorf("Expected pair %d to have distance %g but got %g", i, expected, actual)
+				}
+				if expected, actual := distanceBetweenPoints(p.Left, p.Right), p.Distance; expected != actual {
+					t.Errorf("Expected distance %g between %v and %v but got %g", expected, p.Left, p.Right, actual)
+				}
+			}
+		}
+	})
+
+	t.Run("returns pairs at zero distance first", func(t *testing.T) {
+		left := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		leftPoints := []Point{{10, 0, 0}, {1, 2, 3}}
+		_, _ = insertPoints(leftPoints, left)
+
+		right := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		rightPoints := []Point{{20, 0, 0}, {1, 2, 3}}
+		_, _ = insertPoints(rightPoints, right)
+
+		pairs, err := ClosestPairs(left, right, 1)
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if expected, actual := 1, len(pairs); expected != actual {
+			t.Fatalf("Expected %d pair but got %d", expected, actual)
+		}
+		if expected, actual := (Pair[*Point]{&leftPoints[1], &rightPoints[1], 0}), pairs[0]; expected != actual {
+			t.Errorf("Expected pair %v but got %v", expected, actual)
+		}
+	})
+
+	t.Run("returns errors from either store", func(t *testing.T) {
+		storeErr := errors.New("store failure")
+		failing, _ := NewTreeWithStore[*Point](&failingStore{err: storeErr}, 2, 1000.0, distanceBetweenPoints)
+
+		populated := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		_, _ = insertPoints(randomPoints(10), populated)
+
+		for _, trees := range [][2]*Tree[*Point]{{failing, populated}, {populated, failing}} {
+			_, err := ClosestPairs(trees[0], trees[1], 5)
+
+			if expected, actual := storeErr, err; expected != actual {
+				t.Errorf("Expected error %v but got %v", expected, actual)
+			}
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package typed
+
+// Cluster represents a group of items in a Tree which are covered by a single
+// centre item.
+type Cluster[T any] struct {
+	Centre  T
+	Members []T
+}
+
+// Clusters returns a clustering of the items in the tree, using the items which
+// are present at or above the specified level as the cluster centres.
+//
+// Every other item is a member of the cluster of the centre which covers it in
+// the tree, and is within the tree’s basis raised to the power of the level
+// from that centre. Lower levels therefore give more, smaller clusters, and
+// each cluster is wholly contained by a cluster at any higher level. Note that
+// centres are not guaranteed to be separated by any minimum distance, as the
+// tree does not strictly enforce separation when items are inserted.
+//
+// If the level is above the level of the tree’s roots, the roots are used as
+// the centres. Members do not include the centre itself.
+//
+// Multiple calls to Clusters and Insert are safe to make concurrently.
+func (t *Tree[T]) Clusters(level int) (clusters []Cluster[T], err error) {
+	err = traverseBreadthFirst(t, t.NewTracer(), true, func(item T, parent *clusterMembership, itemLevel int) (*clusterMembership, error) {
+		if parent == nil || (parent.isCentre && itemLevel >= level) {
+			clusters = append(clusters, Cluster[T]{Centre: item})
+			return &clusterMembership{len(clusters) - 1, true}, nil
+		}
+
+		clusters[parent.clusterIndex].Members = append(clusters[parent.clusterIndex].Members, item)
+		return &clusterMembership{parent.clusterIndex, false}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return clusters, nil
+}
+
+// clusterMembership identifies the cluster to which an item belongs, and
+// whether the item is the cluster’s centre.
+type clusterMembership struct {
+	clusterIndex int
+	isCentre     bool
+}
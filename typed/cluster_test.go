@@ -0,0 +1,109 @@
+package typed
+
+import (
+	"testing"
+)
+
+func TestClusters(t *testing.T) {
+
+	t.Run("returns no clusters for an empty tree", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		clusters, err := tree.Clusters(5)
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if expected, actual := 0, len(clusters); expected != actual {
+			t.Errorf("Expected %d clusters but got %d", expected, actual)
+		}
+	})
+
+	t.Run("with a populated tree", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		points := randomPoints(500)
+		_, _ = insertPoints(points, tree)
+
+		expectPartition := func(t *testing.T, clusters []Cluster[*Point]) {
+			t.Helper()
+
+			seen := make(map[*Point]int)
+			for _, c := range clusters {
+				seen[c.Centre]++
+				for _, m := range c.Members {
+					seen[m]++
+				}
+			}
+
+			if expected, actual := len(points), len(seen); expected != actual {
+				t.Errorf("Expected %d distinct items across clusters but found %d", expected, actual)
+			}
+			for item, count := range seen {
+				if count != 1 {
+					t.Errorf("Expected item %v to appear once but appeared %d times", item, count)
+				}
+			}
+		}
+
+		t.Run("uses only the roots as centres at the root level", func(t *testing.T) {
+			clusters, err := tree.Clusters(tree.rootLevel)
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			roots, _ := tree.store.LoadChildren(nil)
+			if expected, actual := len(roots[0].itemsAt(tree.rootLevel)), len(clusters); expected != actual {
+				t.Errorf("Expected %d clusters but got %d", expected, actual)
+			}
+			expectPartition(t, clusters)
+		})
+
+		t.Run("produces more clusters at lower levels", func(t *testing.T) {
+			previousCount := 0
+
+			for level := tree.rootLevel; level > tree.rootLevel-8; level-- {
+				clusters, err := tree.Clusters(level)
+				if err != nil {
+					t.Fatalf("Expected success but got error: %v", err)
+				}
+
+				if len(clusters) < previousCount {
+					t.Errorf("Expected at least %d clusters at level %d but got %d", previousCount, level, len(clusters))
+				}
+				previousCount = len(clusters)
+
+				expectPartition(t, clusters)
+			}
+		})
+
+		t.Run("covers members according to the level", func(t *testing.T) {
+			const level = 6
+
+			clusters, err := tree.Clusters(level)
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+
+			for _, c := range clusters {
+				for _, m := range c.Members {
+					if dist := distanceBetweenPoints(c.Centre, m); dist > tree.distanceForLevel(level) {
+						t.Errorf("Expected member %v to be within %g of centre %v but was %g away", m, tree.distanceForLevel(level), c.Centre, dist)
+					}
+				}
+			}
+		})
+
+		t.Run("makes every item a centre at a sufficiently low level", func(t *testing.T) {
+			clusters, err := tree.Clusters(-100)
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if expected, actual := len(points), len(clusters); expected != actual {
+				t.Errorf("Expected %d clusters but got %d", expected, actual)
+			}
+			expectPartition(t, clusters)
+		})
+	})
+}
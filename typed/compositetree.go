@@ -0,0 +1,170 @@
+package typed
+
+import (
+	"context"
+	"github.com/mandykoh/go-parallel"
+	"sync"
+	"sync/atomic"
+)
+
+// CompositeTree spreads operations across multiple subtrees for scaling and
+// parallelisation.
+type CompositeTree[T any] struct {
+	trees       []*Tree[T]
+	insertCount uint32
+}
+
+// FindNearest returns the nearest items in all the subtrees to the specified
+// query item, up to the specified maximum number of results and maximum
+// distance.
+//
+// Subtrees are queried in parallel.
+//
+// Results are returned with their distances from the query item, in order from
+// closest to furthest.
+//
+// If no items are found matching the given criteria, an empty result set is
+// returned.
+//
+// Multiple calls to FindNearest and Insert are safe to make concurrently.
+func (ct *CompositeTree[T]) FindNearest(query T, maxResults int, maxDistance float64) (results []ItemWithDistance[T], err error) {
+	return ct.FindNearestContext(context.Background(), query, maxResults, maxDistance)
+}
+
+// FindNearestContext returns the nearest items in all the subtrees to the
+// specified query item, up to the specified maximum number of results and
+// maximum distance.
+//
+// Subtrees are queried in parallel. If the context is cancelled or its deadline
+// expires before all subtrees have been searched, the search is abandoned and
+// the context’s error is returned.
+//
+// Results are returned with their distances from the query item, in order from
+// closest to furthest.
+//
+// If no items are found matching the given criteria, an empty result set is
+// returned.
+//
+// Multiple calls to FindNearestContext and InsertContext are safe to make
+// concurrently.
+func (ct *CompositeTree[T]) FindNearestContext(ctx context.Context, query T, maxResults int, maxDistance float64) (results []ItemWithDistance[T], err error) {
+
+	subResults := make([][]ItemWithDistance[T], len(ct.trees))
+	var subErr error
+	var subErrMutex sync.Mutex
+
+	parallel.RunWorkers(len(subResults), func(workerNum, workerCount int) {
+		results, err := ct.trees[workerNum].FindNearestContext(ctx, query, maxResults, maxDistance)
+		if err != nil {
+			subErrMutex.Lock()
+			subErr = err
+			subErrMutex.Unlock()
+			return
+		}
+
+		subResults[workerNum] = results
+	})
+	if subErr != nil {
+		return nil, subErr
+	}
+
+	return zipItemsWithDistance(subResults, maxResults), nil
+}
+
+// Insert inserts the specified item into one of the subtrees.
+//
+// Multiple calls to FindNearest and Insert are safe to make concurrently.
+func (ct *CompositeTree[T]) Insert(item T) (err error) {
+	return ct.InsertContext(context.Background(), item)
+}
+
+// InsertContext inserts the specified item into one of the subtrees.
+//
+// If the context is cancelled or its deadline expires before the item is
+// inserted, the insertion is abandoned and the context’s error is returned.
+//
+// Multiple calls to FindNearestContext and InsertContext are safe to make
+// concurrently.
+func (ct *CompositeTree[T]) InsertContext(ctx context.Context, item T) (err error) {
+	treeIndex := atomic.AddUint32(&ct.insertCount, 1) % uint32(len(ct.trees))
+	return ct.trees[treeIndex].InsertContext(ctx, item)
+}
+
+// Remove removes the given item from whichever subtree contains it. If no such
+// item exists in any of the subtrees, this has no effect.
+//
+// removed will be the item that was successfully removed, and ok reports
+// whether a matching item was found.
+//
+// This method is not safe for concurrent use. Calls to Remove should be
+// externally synchronised so they do not execute concurrently with each other
+// or with calls to FindNearest or Insert.
+func (ct *CompositeTree[T]) Remove(item T) (removed T, ok bool, err error) {
+	return ct.RemoveContext(context.Background(), item)
+}
+
+// RemoveContext removes the given item from whichever subtree contains it. If
+// no such item exists in any of the subtrees, this has no effect.
+//
+// Subtrees are searched in turn. If the context is cancelled or its deadline
+// expires before the item is found, the removal is abandoned and the context’s
+// error is returned.
+//
+// removed will be the item that was successfully removed, and ok reports
+// whether a matching item was found.
+//
+// This method is not safe for concurrent use. Calls to RemoveContext should be
+// externally synchronised so they do not execute concurrently with each other
+// or with calls to other methods.
+func (ct *CompositeTree[T]) RemoveContext(ctx context.Context, item T) (removed T, ok bool, err error) {
+	for _, tree := range ct.trees {
+		removed, ok, err = tree.RemoveContext(ctx, item)
+		if ok || err != nil {
+			return
+		}
+	}
+
+	return removed, false, nil
+}
+
+func NewCompositeTree[T any](trees ...*Tree[T]) *CompositeTree[T] {
+	return &CompositeTree[T]{
+		trees: trees,
+	}
+}
+
+func zipItemsWithDistance[T any](itemSets [][]ItemWithDistance[T], limit int) []ItemWithDistance[T] {
+	var results []ItemWithDistance[T]
+
+	itemIndices := make([]int, len(itemSets))
+
+	for itemCount := 0; itemCount < limit; itemCount++ {
+
+		// Find the minimum item across all item sets
+		minItemSet := 0
+		var minItem *ItemWithDistance[T]
+
+		for i := range itemSets {
+			itemIndex := itemIndices[i]
+			if itemIndex >= len(itemSets[i]) {
+				continue
+			}
+
+			item := &itemSets[i][itemIndex]
+			if minItem == nil || item.Distance < minItem.Distance {
+				minItem = item
+				minItemSet = i
+			}
+		}
+
+		// No minimum item found - no more items, so stop
+		if minItem == nil {
+			break
+		}
+
+		itemIndices[minItemSet]++
+		results = append(results, *minItem)
+	}
+
+	return results
+}
@@ -0,0 +1,184 @@
+package typed
+
+import "math"
+
+type coverSet[T any] struct {
+	layers           []coverSetLayer[T]
+	totalItemCount   int
+	visibleItemCount int
+}
+
+func coverSetWithItems[T any](items []T, parent *T, query T, distanceFunc DistanceFunc[T], loadChildren func(...*T) ([]LevelsWithItems[T], error)) (coverSet[T], error) {
+	var cs coverSet[T]
+
+	if len(items) > 0 {
+		children, err := loadChildren(parentsFor(items)...)
+		if err != nil {
+			return cs, err
+		}
+
+		itemsForLayer := make([]itemWithChildren[T], len(items))
+		for i, item := range items {
+			distance := distanceFunc(item, query)
+			itemsForLayer[i] = itemWithChildren[T]{withDistance: ItemWithDistance[T]{item, distance}, parent: parent, children: children[i], id: i}
+		}
+
+		cs.addLayer(makeCoverSetLayer(itemsForLayer))
+	}
+
+	return cs, nil
+}
+
+func (cs *coverSet[T]) addLayer(layer coverSetLayer[T]) {
+	cs.layers = append(cs.layers, layer)
+	cs.totalItemCount += len(layer)
+	cs.visibleItemCount += len(layer)
+}
+
+func (cs coverSet[T]) atBottom() bool {
+	for _, layer := range cs.layers {
+		for _, csItem := range layer {
+			if csItem.hasChildren() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (cs *coverSet[T]) addPromotedChildren(promotedChildren []itemWithChildren[T], grandchildren []LevelsWithItems[T]) {
+	for i := range promotedChildren {
+		promotedChildren[i].children = grandchildren[i]
+	}
+
+	cs.addLayer(makeCoverSetLayer(promotedChildren))
+}
+
+func (cs coverSet[T]) child(query T, distThreshold float64, childLevel int, distanceBetween DistanceFunc[T], loadChildren func(...*T) ([]LevelsWithItems[T], error)) (childCoverSet coverSet[T], parentWithinThreshold *T, err error) {
+	childCoverSet, promotedChildren, parentWithinThreshold := cs.promoteChildren(query, distThreshold, childLevel, distanceBetween)
+
+	if len(promotedChildren) > 0 {
+		children := make([]*T, len(promotedChildren))
+		for i := range promotedChildren {
+			children[i] = &promotedChildren[i].withDistance.Item
+		}
+
+		grandchildren, err := loadChildren(children...)
+		if err != nil {
+			return childCoverSet, nil, err
+		}
+
+		childCoverSet.addPromotedChildren(promotedChildren, grandchildren)
+	}
+
+	return
+}
+
+func (cs coverSet[T]) bound(maxItems int, maxDist float64, matches MatchFunc[T]) float64 {
+	var count = 0
+	var minIndices = make([]int, len(cs.layers))
+	var boundDistance = maxDist
+
+	for count < maxItems {
+
+		var minLayerIndex = -1
+		for layerIndex, layer := range cs.layers {
+			minIndex := minIndices[layerIndex]
+			if minIndex >= len(layer) {
+				continue
+			}
+
+			itemDistance := layer[minIndex].withDistance.Distance
+			if minLayerIndex == -1 || itemDistance < boundDistance {
+				minLayerIndex = layerIndex
+				boundDistance = itemDistance
+			}
+		}
+
+		if minLayerIndex == -1 || boundDistance > maxDist {
+			break
+		}
+
+		if matches == nil || matches(cs.layers[minLayerIndex][minIndices[minLayerIndex]].withDistance.Item) {
+			count++
+		}
+		minIndices[minLayerIndex]++
+	}
+
+	if count == maxItems {
+		return boundDistance
+	}
+
+	return maxDist
+}
+
+func (cs coverSet[T]) closest(maxItems int, maxDist float64, matches MatchFunc[T]) []ItemWithDistance[T] {
+	var results []ItemWithDistance[T]
+	var minIndices = make([]int, len(cs.layers))
+
+	for len(results) < maxItems {
+
+		var minItem *ItemWithDistance[T]
+		var minLayerIndex = -1
+		for layerIndex, layer := range cs.layers {
+			minIndex := minIndices[layerIndex]
+			if minIndex >= len(layer) {
+				continue
+			}
+
+			item := &layer[minIndex].withDistance
+			if minLayerIndex == -1 || item.Distance < minItem.Distance {
+				minLayerIndex = layerIndex
+				minItem = item
+			}
+		}
+
+		if minLayerIndex == -1 || minItem.Distance > maxDist {
+			break
+		}
+
+		if matches == nil || matches(minItem.Item) {
+			results = append(results, *minItem)
+		}
+		minIndices[minLayerIndex]++
+	}
+
+	return results
+}
+
+func (cs coverSet[T]) promoteChildren(query T, distThreshold float64, childLevel int, distanceBetween DistanceFunc[T]) (childCoverSet coverSet[T], promotedChildren []itemWithChildren[T], parentWithinThreshold *T) {
+	childCoverSet = coverSet[T]{
+		layers:           cs.layers,
+		totalItemCount:   cs.totalItemCount,
+		visibleItemCount: 0,
+	}
+
+	var minParentDistance = math.MaxFloat64
+
+	for i := range cs.layers {
+		layer := cs.layers[i].constrainedToDistance(distThreshold)
+		childCoverSet.layers[i] = layer
+		childCoverSet.visibleItemCount += len(layer)
+
+		if len(layer) > 0 && layer[0].withDistance.Distance < minParentDistance {
+			parentWithinThreshold = &layer[0].withDistance.Item
+			minParentDistance = layer[0].withDistance.Distance
+		}
+
+		for j := range layer {
+			csItem := &layer[j]
+			for position, childItem := range csItem.takeChildrenAt(childLevel) {
+				if childDist := distanceBetween(childItem, query); childDist <= distThreshold {
+					promotedChild := itemWithChildren[T]{withDistance: ItemWithDistance[T]{childItem, childDist}, parent: &csItem.withDistance.Item, source: childSource{csItem.id, position}}
+					promotedChildren = append(promotedChildren, promotedChild)
+				}
+			}
+		}
+	}
+
+	return
+}
+
+func (cs coverSet[T]) within(maxDist float64) []ItemWithDistance[T] {
+	return cs.closest(cs.totalItemCount, maxDist, nil)
+}
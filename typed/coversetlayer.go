@@ -1,12 +1,12 @@
-package covertree
+package typed
 
 import (
 	"sort"
 )
 
-type coverSetLayer []itemWithChildren
+type coverSetLayer[T any] []itemWithChildren[T]
 
-func (l coverSetLayer) constrainedToDistance(distance float64) coverSetLayer {
+func (l coverSetLayer[T]) constrainedToDistance(distance float64) coverSetLayer[T] {
 	cutOff := sort.Search(len(l), func(i int) bool {
 		return l[i].withDistance.Distance > distance
 	})
@@ -14,7 +14,7 @@ func (l coverSetLayer) constrainedToDistance(distance float64) coverSetLayer {
 	return l[:cutOff]
 }
 
-func makeCoverSetLayer(items []itemWithChildren) coverSetLayer {
+func makeCoverSetLayer[T any](items []itemWithChildren[T]) coverSetLayer[T] {
 	sort.Slice(items, func(i, j int) bool {
 		return items[i].withDistance.Distance < items[j].withDistance.Distance
 	})
@@ -1,4 +1,4 @@
-package covertree
+package typed
 
 import (
 	"math/rand"
@@ -11,21 +11,21 @@ func TestCoverSetLayer(t *testing.T) {
 	t.Run("constrainedToDistance()", func(t *testing.T) {
 
 		t.Run("returns a new coverSetLayer containing only items within the specified distance", func(t *testing.T) {
-			items := []itemWithChildren{
+			items := []itemWithChildren[string]{
 				{
-					withDistance: ItemWithDistance{
+					withDistance: ItemWithDistance[string]{
 						Item:     "item1",
 						Distance: 0.0,
 					},
 				},
 				{
-					withDistance: ItemWithDistance{
+					withDistance: ItemWithDistance[string]{
 						Item:     "item2",
 						Distance: 1.0,
 					},
 				},
 				{
-					withDistance: ItemWithDistance{
+					withDistance: ItemWithDistance[string]{
 						Item:     "item3",
 						Distance: 2.0,
 					},
@@ -74,21 +74,21 @@ func TestCoverSetLayer(t *testing.T) {
 	t.Run("makeCoverSetLayer()", func(t *testing.T) {
 
 		t.Run("creates layer with items in sorted order by distance", func(t *testing.T) {
-			items := []itemWithChildren{
+			items := []itemWithChildren[string]{
 				{
-					withDistance: ItemWithDistance{
+					withDistance: ItemWithDistance[string]{
 						Item:     "item1",
 						Distance: rand.Float64(),
 					},
 				},
 				{
-					withDistance: ItemWithDistance{
+					withDistance: ItemWithDistance[string]{
 						Item:     "item2",
 						Distance: rand.Float64(),
 					},
 				},
 				{
-					withDistance: ItemWithDistance{
+					withDistance: ItemWithDistance[string]{
 						Item:     "item3",
 						Distance: rand.Float64(),
 					},
@@ -101,7 +101,7 @@ func TestCoverSetLayer(t *testing.T) {
 
 			layer := makeCoverSetLayer(items)
 
-			itemsSorted := make([]itemWithChildren, len(items))
+			itemsSorted := make([]itemWithChildren[string], len(items))
 			copy(itemsSorted, items)
 			sort.Slice(itemsSorted, func(i, j int) bool {
 				return itemsSorted[i].withDistance.Distance < itemsSorted[j].withDistance.Distance
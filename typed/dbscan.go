@@ -0,0 +1,110 @@
+package typed
+
+// DBSCANResult represents the outcome of density-based clustering of the items
+// in a Tree.
+//
+// Clusters contains the items of each cluster that was found. Noise contains
+// the items which do not belong to any cluster.
+type DBSCANResult[T any] struct {
+	Clusters [][]T
+	Noise    []T
+}
+
+// DBSCAN performs density-based spatial clustering of the items in the
+// specified tree, using radius searches on the tree to find the neighbourhood
+// of each item.
+//
+// Items with at least minPoints items (including themselves) within eps are
+// core items. Clusters consist of core items within eps of each other, along
+// with any other items within eps of those core items. All remaining items are
+// considered noise.
+//
+// progress, if not nil, is called after each item has been processed with the
+// number of items processed so far and the total number of items in the tree.
+//
+// Items are used as map keys, so items which are equal are always placed
+// together, in the same cluster or as noise. The tree should not be modified
+// while clustering is in progress.
+func DBSCAN[T comparable](tree *Tree[T], eps float64, minPoints int, progress func(processed, total int)) (result DBSCANResult[T], err error) {
+	const noise = -1
+
+	items, err := tree.allItems(tree.NewTracer())
+	if err != nil {
+		return result, err
+	}
+
+	// Equal items share a label, so each label accounts for every copy
+	copies := make(map[T]int, len(items))
+	for _, item := range items {
+		copies[item]++
+	}
+
+	// Cluster labels are offset by one so that the zero value means unlabelled
+	labels := make(map[T]int, len(copies))
+	processed := 0
+
+	reportProgress := func(item T) {
+		processed += copies[item]
+		if progress != nil {
+			progress(processed, len(items))
+		}
+	}
+
+	for _, item := range items {
+		if labels[item] != 0 {
+			continue
+		}
+
+		neighbours, err := tree.FindWithin(item, eps)
+		if err != nil {
+			return result, err
+		}
+
+		if len(neighbours) < minPoints {
+			labels[item] = noise
+			reportProgress(item)
+			continue
+		}
+
+		result.Clusters = append(result.Clusters, nil)
+		label := len(result.Clusters)
+		labels[item] = label
+		reportProgress(item)
+
+		seeds := neighbours
+		for len(seeds) > 0 {
+			seed := seeds[0].Item
+			seeds = seeds[1:]
+
+			switch labels[seed] {
+			case noise:
+				labels[seed] = label
+				continue
+			case 0:
+			default:
+				continue
+			}
+
+			labels[seed] = label
+			reportProgress(seed)
+
+			seedNeighbours, err := tree.FindWithin(seed, eps)
+			if err != nil {
+				return result, err
+			}
+			if len(seedNeighbours) >= minPoints {
+				seeds = append(seeds, seedNeighbours...)
+			}
+		}
+	}
+
+	for _, item := range items {
+		if label := labels[item]; label == noise {
+			result.Noise = append(result.Noise, item)
+		} else {
+			result.Clusters[label-1] = append(result.Clusters[label-1], item)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,162 @@
+package typed
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDBSCAN(t *testing.T) {
+
+	t.Run("returns no clusters or noise for an empty tree", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		result, err := DBSCAN(tree, 1.0, 2, nil)
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if expected, actual := 0, len(result.Clusters); expected != actual {
+			t.Errorf("Expected %d clusters but got %d", expected, actual)
+		}
+		if expected, actual := 0, len(result.Noise); expected != actual {
+			t.Errorf("Expected %d noise items but got %d", expected, actual)
+		}
+	})
+
+	t.Run("with separated groups of points", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		var groups [][]Point
+		for _, centre := range []Point{{0, 0, 0}, {100, 0, 0}, {0, 100, 0}} {
+			var group []Point
+			for i := 0; i < 10; i++ {
+				group = append(group, Point{centre[0] + float64(i), centre[1], centre[2]})
+			}
+			groups = append(groups, group)
+		}
+		outliers := []Point{{50, 50, 50}, {-50, -50, -50}}
+
+		for _, group := range groups {
+			_, _ = insertPoints(group, tree)
+		}
+		_, _ = insertPoints(outliers, tree)
+
+		clusterOf := func(result DBSCANResult[*Point]) map[*Point]int {
+			clusterOf := make(map[*Point]int)
+			for i, c := range result.Clusters {
+				for _, item := range c {
+					clusterOf[item] = i
+				}
+			}
+			return clusterOf
+		}
+
+		t.Run("finds each group as a cluster", func(t *testing.T) {
+			result, err := DBSCAN(tree, 1.5, 3, nil)
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+
+			if expected, actual := len(groups), len(result.Clusters); expected != actual {
+				t.Fatalf("Expected %d clusters but got %d", expected, actual)
+			}
+
+			clusterOf := clusterOf(result)
+			for _, group := range groups {
+				first := clusterOf[&group[0]]
+				for i := range group {
+					if cluster, ok := clusterOf[&group[i]]; !ok || cluster != first {
+						t.Errorf("Expected %v to be in the same cluster as %v", &group[i], &group[0])
+					}
+				}
+				if expected, actual := len(group), len(result.Clusters[first]); expected != actual {
+					t.Errorf("Expected cluster to have %d items but got %d", expected, actual)
+				}
+			}
+		})
+
+		t.Run("reports isolated points as noise", func(t *testing.T) {
+			result, err := DBSCAN(tree, 1.5, 3, nil)
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+
+			if expected, actual := len(outliers), len(result.Noise); expected != actual {
+				t.Fatalf("Expected %d noise items but got %d", expected, actual)
+			}
+			for i := range outliers {
+				found := false
+				for _, item := range result.Noise {
+					found = found || item == &outliers[i]
+				}
+				if !found {
+					t.Errorf("Expected %v to be noise but was not", &outliers[i])
+				}
+			}
+		})
+
+		t.Run("includes border points which are not core points", func(t *testing.T) {
+			// The end points of each group have too few neighbours to be core points
+			result, err := DBSCAN(tree, 1.5, 3, nil)
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+
+			clusterOf := clusterOf(result)
+			for _, group := range groups {
+				for _, p := range []*Point{&group[0], &group[len(group)-1]} {
+					if _, ok := clusterOf[p]; !ok {
+						t.Errorf("Expected border point %v to be in a cluster", p)
+					}
+				}
+			}
+		})
+
+		t.Run("treats everything as noise when the density requirement is unreachable", func(t *testing.T) {
+			result, err := DBSCAN(tree, 1.5, 100, nil)
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+
+			if expected, actual := 0, len(result.Clusters); expected != actual {
+				t.Errorf("Expected %d clusters but got %d", expected, actual)
+			}
+			if expected, actual := 3*10+len(outliers), len(result.Noise); expected != actual {
+				t.Errorf("Expected %d noise items but got %d", expected, actual)
+			}
+		})
+
+		t.Run("reports progress for each item", func(t *testing.T) {
+			total := 3*10 + len(outliers)
+			calls := 0
+
+			_, err := DBSCAN(tree, 1.5, 3, func(processed, reportedTotal int) {
+				calls++
+				if expected, actual := calls, processed; expected != actual {
+					t.Errorf("Expected %d items processed but got %d", expected, actual)
+				}
+				if expected, actual := total, reportedTotal; expected != actual {
+					t.Errorf("Expected total of %d but got %d", expected, actual)
+				}
+			})
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+
+			if expected, actual := total, calls; expected != actual {
+				t.Errorf("Expected %d progress reports but got %d", expected, actual)
+			}
+		})
+	})
+
+	t.Run("returns errors from the store", func(t *testing.T) {
+		storeErr := errors.New("store failure")
+		tree, _ := NewTreeWithStore[*Point](&failingStore{err: storeErr}, 2, 1000.0, distanceBetweenPoints)
+
+		_, err := DBSCAN(tree, 1.0, 2, nil)
+
+		if expected, actual := storeErr, err; expected != actual {
+			t.Errorf("Expected error %v but got %v", expected, actual)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package typed
+
+import "sort"
+
+// Dendrogram represents a single-linkage hierarchical clustering of items, as
+// a sequence of merges between clusters in order of increasing distance.
+//
+// Clusters are identified by index. Indices below len(Items) identify the
+// cluster containing only the item at that index, while the index
+// len(Items)+i identifies the cluster formed by Merges[i].
+type Dendrogram[T any] struct {
+	Items  []T
+	Merges []Merge
+}
+
+// Merge represents the joining of two clusters in a Dendrogram at a given
+// distance, forming a new cluster of the given size.
+type Merge struct {
+	Left     int
+	Right    int
+	Distance float64
+	Size     int
+}
+
+// NewDendrogram creates a single-linkage Dendrogram from the edges of a
+// minimum spanning tree, such as those returned by Tree.MinimumSpanningTree.
+//
+// Items are assigned indices in the order they first appear in the edges, and
+// items which are equal are assigned the same index. Items which do not appear
+// in any edge are not part of the Dendrogram.
+func NewDendrogram[T comparable](edges []Edge[T]) *Dendrogram[T] {
+	d := &Dendrogram[T]{}
+
+	sorted := make([]Edge[T], len(edges))
+	copy(sorted, edges)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Distance < sorted[j].Distance
+	})
+
+	indices := make(map[T]int)
+	indexOf := func(item T) int {
+		if i, ok := indices[item]; ok {
+			return i
+		}
+		i := len(d.Items)
+		indices[item] = i
+		d.Items = append(d.Items, item)
+		return i
+	}
+
+	for _, e := range sorted {
+		indexOf(e.From)
+		indexOf(e.To)
+	}
+
+	components := newUnionFind(len(d.Items))
+	clusters := make([]int, len(d.Items))
+	sizes := make([]int, len(d.Items))
+	for i := range clusters {
+		clusters[i] = i
+		sizes[i] = 1
+	}
+
+	for _, e := range sorted {
+		from, to := components.find(indices[e.From]), components.find(indices[e.To])
+		if !components.union(from, to) {
+			continue
+		}
+
+		d.Merges = append(d.Merges, Merge{
+			Left:     clusters[from],
+			Right:    clusters[to],
+			Distance: e.Distance,
+			Size:     sizes[from] + sizes[to],
+		})
+
+		clusters[from] = len(d.Items) + len(d.Merges) - 1
+		sizes[from] += sizes[to]
+	}
+
+	return d
+}
+
+// Cut returns the clusters of the Dendrogram formed by all merges at or below
+// the specified distance. Items within each cluster, and the clusters
+// themselves, are returned in order of item index.
+func (d *Dendrogram[T]) Cut(distance float64) [][]T {
+	components := newUnionFind(len(d.Items))
+
+	// Each merged cluster is represented by an item within it
+	representatives := make([]int, len(d.Items), len(d.Items)+len(d.Merges))
+	for i := range representatives {
+		representatives[i] = i
+	}
+
+	for _, m := range d.Merges {
+		left, right := representatives[m.Left], representatives[m.Right]
+		representatives = append(representatives, left)
+
+		if m.Distance <= distance {
+			components.union(left, right)
+		}
+	}
+
+	var clusters [][]T
+	clusterIndices := make(map[int]int)
+
+	for i, item := range d.Items {
+		root := components.find(i)
+
+		c, ok := clusterIndices[root]
+		if !ok {
+			c = len(clusters)
+			clusterIndices[root] = c
+			clusters = append(clusters, nil)
+		}
+
+		clusters[c] = append(clusters[c], item)
+	}
+
+	return clusters
+}
@@ -0,0 +1,150 @@
+package typed
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDendrogram(t *testing.T) {
+
+	t.Run("NewDendrogram()", func(t *testing.T) {
+
+		t.Run("merges clusters in order of increasing distance", func(t *testing.T) {
+			d := NewDendrogram([]Edge[string]{
+				{"c", "d", 3.0},
+				{"a", "b", 1.0},
+				{"b", "c", 2.0},
+			})
+
+			if expected, actual := 4, len(d.Items); expected != actual {
+				t.Fatalf("Expected %d items but got %d", expected, actual)
+			}
+			for i, item := range []string{"a", "b", "c", "d"} {
+				if expected, actual := item, d.Items[i]; expected != actual {
+					t.Errorf("Expected item %d to be %v but was %v", i, expected, actual)
+				}
+			}
+
+			expectedMerges := []Merge{
+				{Left: 0, Right: 1, Distance: 1.0, Size: 2},
+				{Left: 4, Right: 2, Distance: 2.0, Size: 3},
+				{Left: 5, Right: 3, Distance: 3.0, Size: 4},
+			}
+
+			if expected, actual := len(expectedMerges), len(d.Merges); expected != actual {
+				t.Fatalf("Expected %d merges but got %d", expected, actual)
+			}
+			for i := range expectedMerges {
+				if expected, actual := expectedMerges[i], d.Merges[i]; expected != actual {
+					t.Errorf("Expected merge %d to be %+v but was %+v", i, expected, actual)
+				}
+			}
+		})
+
+		t.Run("returns an empty dendrogram when there are no edges", func(t *testing.T) {
+			d := NewDendrogram[string](nil)
+
+			if expected, actual := 0, len(d.Items); expected != actual {
+				t.Errorf("Expected %d items but got %d", expected, actual)
+			}
+			if expected, actual := 0, len(d.Merges); expected != actual {
+				t.Errorf("Expected %d merges but got %d", expected, actual)
+			}
+		})
+	})
+
+	t.Run("Cut()", func(t *testing.T) {
+
+		expectClusters := func(t *testing.T, expected, actual [][]string) {
+			t.Helper()
+
+			if len(expected) != len(actual) {
+				t.Fatalf("Expected clusters %v but got %v", expected, actual)
+			}
+			for i := range expected {
+				if len(expected[i]) != len(actual[i]) {
+					t.Fatalf("Expected clusters %v but got %v", expected, actual)
+				}
+				for j := range expected[i] {
+					if expected[i][j] != actual[i][j] {
+						t.Fatalf("Expected clusters %v but got %v", expected, actual)
+					}
+				}
+			}
+		}
+
+		d := NewDendrogram([]Edge[string]{
+			{"a", "b", 1.0},
+			{"c", "d", 1.5},
+			{"b", "c", 4.0},
+			{"d", "e", 2.0},
+		})
+
+		t.Run("keeps every item separate below the shortest merge", func(t *testing.T) {
+			expectClusters(t, [][]string{{"a"}, {"b"}, {"c"}, {"d"}, {"e"}}, d.Cut(0.5))
+		})
+
+		t.Run("includes merges at exactly the cut distance", func(t *testing.T) {
+			expectClusters(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, d.Cut(1.5))
+		})
+
+		t.Run("groups items joined by merges below the cut distance", func(t *testing.T) {
+			expectClusters(t, [][]string{{"a", "b"}, {"c", "d", "e"}}, d.Cut(3.0))
+		})
+
+		t.Run("joins every item above the longest merge", func(t *testing.T) {
+			expectClusters(t, [][]string{{"a", "b", "c", "d", "e"}}, d.Cut(math.Inf(1)))
+		})
+	})
+
+	t.Run("matches single-linkage clustering of a tree", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		points := randomPoints(200)
+		_, _ = insertPoints(points, tree)
+
+		edges, err := tree.MinimumSpanningTree()
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+
+		const cutDistance = 100.0
+		clusters := NewDendrogram(edges).Cut(cutDistance)
+
+		// Single-linkage clusters are the connected components of the graph of
+		// items within the cut distance of each other
+		parents := make([]int, len(points))
+		for i := range parents {
+			parents[i] = i
+		}
+		var find func(int) int
+		find = func(i int) int {
+			if parents[i] != i {
+				parents[i] = find(parents[i])
+			}
+			return parents[i]
+		}
+		for i := range points {
+			for j := range points {
+				if distanceBetweenPoints(&points[i], &points[j]) <= cutDistance {
+					parents[find(i)] = find(j)
+				}
+			}
+		}
+
+		clusterOf := make(map[*Point]int)
+		for c := range clusters {
+			for _, item := range clusters[c] {
+				clusterOf[item] = c
+			}
+		}
+
+		for i := range points {
+			for j := range points {
+				if expected, actual := find(i) == find(j), clusterOf[&points[i]] == clusterOf[&points[j]]; expected != actual {
+					t.Fatalf("Expected %v and %v to be clustered together: %v but got %v", &points[i], &points[j], expected, actual)
+				}
+			}
+		}
+	})
+}
@@ -1,5 +1,5 @@
-package covertree
+package typed
 
 // DistanceFunc represents a function which defines the distance between two
 // items.
-type DistanceFunc func(a, b interface{}) (distance float64)
+type DistanceFunc[T any] func(a, b T) (distance float64)
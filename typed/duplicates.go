@@ -0,0 +1,80 @@
+package typed
+
+// DuplicateGroups finds groups of items in the tree which are duplicates or
+// near-duplicates of each other, where items are considered near-duplicates if
+// they are within the specified threshold distance.
+//
+// Groups are connected, such that every item in a group is within the threshold
+// of at least one other item in the group, but not necessarily of every other
+// item. Items which are not within the threshold of any other item do not
+// belong to a group.
+//
+// Rather than searching the tree separately for each item, the tree is
+// traversed against itself as for AllNearestNeighbours.
+//
+// found is called once for each group with the items in the group. Groups are
+// reported in no particular order. If found returns an error, reporting stops
+// and the error is returned.
+//
+// The tree should not be modified while groups are being found.
+func (t *Tree[T]) DuplicateGroups(threshold float64, found func(group []T) error) error {
+	var items []T
+	var components unionFind
+	indices := make(map[*node[T]]int)
+
+	indexOf := func(n *node[T]) int {
+		i, ok := indices[n]
+		if !ok {
+			i = components.add()
+			indices[n] = i
+			items = append(items, n.item)
+		}
+		return i
+	}
+
+	loadChildren := t.NewTracer().loadChildren
+
+	roots, err := t.loadRootNodes(loadChildren)
+	if err != nil {
+		return err
+	}
+
+	err = t.allNearestNeighbourNodes(roots, -1, threshold, loadChildren, func(query *node[T], neighbours []nodeWithDistance[T]) error {
+		i := indexOf(query)
+		for _, n := range neighbours {
+			components.union(i, indexOf(n.node))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	groupIndices := make(map[int]int)
+	var groups [][]T
+
+	for i, item := range items {
+		root := components.find(i)
+
+		g, ok := groupIndices[root]
+		if !ok {
+			g = len(groups)
+			groupIndices[root] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], item)
+	}
+
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+
+		err = found(group)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
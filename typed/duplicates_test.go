@@ -0,0 +1,214 @@
+package typed
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDuplicateGroups(t *testing.T) {
+
+	collectGroups := func(t *testing.T, tree *Tree[*Point], threshold float64) map[*Point][]*Point {
+		t.Helper()
+
+		groupOf := make(map[*Point][]*Point)
+		err := tree.DuplicateGroups(threshold, func(group []*Point) error {
+			for _, item := range group {
+				if _, ok := groupOf[item]; ok {
+					t.Errorf("Expected %v to be reported in only one group", item)
+				}
+				groupOf[item] = group
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+
+		return groupOf
+	}
+
+	t.Run("reports nothing for an empty tree", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		err := tree.DuplicateGroups(1.0, func(group []*Point) error {
+			t.Errorf("Expected no groups to be reported but got %v", group)
+			return nil
+		})
+
+		if err != nil {
+			t.Errorf("Expected success but got error: %v", err)
+		}
+	})
+
+	t.Run("with duplicates and near-duplicates", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		points := []Point{
+			{0, 0, 0},
+			{0, 0, 0},
+			{0, 0, 0},
+			{100, 0, 0},
+			{101, 0, 0},
+			{102, 0, 0},
+			{0, 100, 0},
+			{50, 50, 50},
+		}
+		_, _ = insertPoints(points, tree)
+
+		t.Run("groups exact duplicates at zero threshold", func(t *testing.T) {
+			groupOf := collectGroups(t, tree, 0)
+
+			if expected, actual := 3, len(groupOf); expected != actual {
+				t.Fatalf("Expected %d grouped items but got %d", expected, actual)
+			}
+			for i := 0; i < 3; i++ {
+				if expected, actual := 3, len(groupOf[&points[i]]); expected != actual {
+					t.Errorf("Expected %v to be in a group of %d but got %d", &points[i], expected, actual)
+				}
+			}
+		})
+
+		t.Run("connects items through chains of near-duplicates", func(t *testing.T) {
+			groupOf := collectGroups(t, tree, 1.0)
+
+			group := groupOf[&points[3]]
+			if expected, actual := 3, len(group); expected != actual {
+				t.Fatalf("Expected a group of %d but got %d", expected, actual)
+			}
+			for i := 3; i < 6; i++ {
+				found := false
+				for _, item := range group {
+					found = found || item == &points[i]
+				}
+				if !found {
+					t.Errorf("Expected %v to be grouped with %v", &points[i], &points[3])
+				}
+			}
+		})
+
+		t.Run("excludes items with no near-duplicates", func(t *testing.T) {
+			groupOf := collectGroups(t, tree, 1.0)
+
+			for _, p := range []*Point{&points[6], &points[7]} {
+				if group, ok := groupOf[p]; ok {
+					t.Errorf("Expected %v not to be grouped but got %v", p, group)
+				}
+			}
+		})
+	})
+
+	t.Run("returns the same groups as a linear search", func(t *testing.T) {
+		const threshold = 30.0
+
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		points := randomPoints(300)
+		_, _ = insertPoints(points, tree)
+
+		// Label connected components by flood filling over all pairs
+		labels := make([]int, len(points))
+		label := 0
+		for i := range points {
+			if labels[i] != 0 {
+				continue
+			}
+			label++
+			labels[i] = label
+
+			pending := []int{i}
+			for len(pending) > 0 {
+				j := pending[0]
+				pending = pending[1:]
+
+				for k := range points {
+					if labels[k] == 0 && distanceBetweenPoints(&points[j], &points[k]) <= threshold {
+						labels[k] = label
+						pending = append(pending, k)
+					}
+				}
+			}
+		}
+
+		sizes := make(map[int]int)
+		labelOf := make(map[*Point]int)
+		for i, l := range labels {
+			sizes[l]++
+			labelOf[&points[i]] = l
+		}
+
+		groupOf := collectGroups(t, tree, threshold)
+
+		for i := range points {
+			group, grouped := groupOf[&points[i]]
+
+			if sizes[labels[i]] == 1 {
+				if grouped {
+					t.Errorf("Expected %v not to be grouped but got %v", &points[i], group)
+				}
+				continue
+			}
+
+			if expected, actual := sizes[labels[i]], len(group); expected != actual {
+				t.Errorf("Expected %v to be in a group of %d but got %d", &points[i], expected, actual)
+			}
+			for _, item := range group {
+				if labels[i] != labelOf[item] {
+					t.Errorf("Expected %v not to be grouped with %v", item, &points[i])
+				}
+			}
+		}
+	})
+
+	t.Run("stops when the callback returns an error", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		_, _ = insertPoints([]Point{{0, 0, 0}, {0, 0, 0}, {100, 0, 0}, {100, 0, 0}}, tree)
+
+		stopErr := errors.New("stop")
+		reported := 0
+
+		err := tree.DuplicateGroups(0, func(group []*Point) error {
+			reported++
+			return stopErr
+		})
+
+		if expected, actual := stopErr, err; expected != actual {
+			t.Errorf("Expected error %v but got %v", expected, actual)
+		}
+		if expected, actual := 1, reported; expected != actual {
+			t.Errorf("Expected %d group to be reported but got %d", expected, actual)
+		}
+	})
+
+	t.Run("returns errors from the store", func(t *testing.T) {
+		storeErr := errors.New("store failure")
+		tree, _ := NewTreeWithStore[*Point](&failingStore{err: storeErr}, 2, 1000.0, distanceBetweenPoints)
+
+		err := tree.DuplicateGroups(1.0, func(group []*Point) error {
+			return nil
+		})
+
+		if expected, actual := storeErr, err; expected != actual {
+			t.Errorf("Expected error %v but got %v", expected, actual)
+		}
+	})
+
+	t.Run("groups duplicates of items which cannot be compared", func(t *testing.T) {
+		tree, _ := newSliceTree([]float64{100}, []float64{10}, []float64{0}, []float64{0})
+
+		var groups [][][]float64
+		err := tree.DuplicateGroups(0, func(group [][]float64) error {
+			groups = append(groups, group)
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if expected, actual := 1, len(groups); expected != actual {
+			t.Fatalf("Expected %d group but got %d", expected, actual)
+		}
+		if expected, actual := 2, len(groups[0]); expected != actual {
+			t.Errorf("Expected a group of %d but got %v", expected, groups[0])
+		}
+	})
+}
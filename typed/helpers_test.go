@@ -0,0 +1,434 @@
+package typed
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func compareWithLinearSearch(tree *Tree[*Point], points []Point, maxResults int, maxDistance float64, coverTreeDistanceCalls *int, t *testing.T) {
+	t.Helper()
+
+	query := randomPoint()
+	fmt.Printf("Query point %v (maxResults: %d, maxDistance: %g)\n", query, maxResults, maxDistance)
+
+	*coverTreeDistanceCalls = 0
+
+	startTime := time.Now()
+	coverTreeResults, err := tree.FindNearest(&query, maxResults, maxDistance)
+	finishTime := time.Now()
+
+	if err != nil {
+		t.Fatalf("Error querying tree: %v", err)
+	}
+
+	fmt.Printf("Cover Tree FindNearest took %d distance comparisons, %dms\n", *coverTreeDistanceCalls, finishTime.Sub(startTime)/time.Millisecond)
+	for _, r := range coverTreeResults {
+		point := *(r.Item)
+		fmt.Printf("Cover Tree FindNearest: %v (distance %g)\n", point, r.Distance)
+	}
+
+	linearSearchResults, linearSearchDistanceCalls := linearSearch(&query, points, maxResults, maxDistance)
+
+	expectSameResults(t, query, coverTreeResults, linearSearchResults)
+
+	if *coverTreeDistanceCalls >= linearSearchDistanceCalls {
+		t.Errorf("Expected cover tree search to require fewer than %d distance comparisons (linear search) but got %d", linearSearchDistanceCalls, *coverTreeDistanceCalls)
+	}
+
+	return
+}
+
+func distanceBetweenPoints(a, b *Point) float64 {
+	total := 0.0
+	for i := 0; i < len(a); i++ {
+		diff := b[i] - a[i]
+		total += diff * diff
+	}
+
+	return math.Sqrt(total)
+}
+
+func distanceBetweenPointsWithCounter(counter *int) DistanceFunc[*Point] {
+	return func(a, b *Point) float64 {
+		*counter++
+		return distanceBetweenPoints(a, b)
+	}
+}
+
+func expectSameResults(t *testing.T, query Point, actualResults []ItemWithDistance[*Point], expectedResults []ItemWithDistance[*Point]) {
+	t.Helper()
+
+	if expected, actual := len(expectedResults), len(actualResults); expected != actual {
+		t.Fatalf("Expected %d results for %v but got %d instead", expected, query, actual)
+
+	}
+
+	availableResults := len(expectedResults)
+	if len(actualResults) < availableResults {
+		availableResults = len(actualResults)
+	}
+
+	for i := 0; i < availableResults; i++ {
+		expectedResult := expectedResults[i]
+		actualResult := actualResults[i]
+
+		if expected, actual := expectedResult.Item, actualResult.Item; expected != actual {
+			t.Errorf("Expected nearest point %d to %v to be %v but got %v", i, query, *expected, *actual)
+		}
+		if expected, actual := expectedResult.Distance, actualResult.Distance; expected != actual {
+			t.Errorf("Expected distance of nearest point %d to %v to be %v but got %v", i, query, expected, actual)
+		}
+	}
+}
+
+type contextRecordingStore struct {
+	inMemoryStore[*Point]
+	contexts []context.Context
+}
+
+func newContextRecordingStore(distanceFunc DistanceFunc[*Point]) *contextRecordingStore {
+	return &contextRecordingStore{inMemoryStore: *NewInMemoryStore(distanceFunc)}
+}
+
+func (s *contextRecordingStore) AddItemContext(ctx context.Context, item *Point, parent **Point, level int) error {
+	s.contexts = append(s.contexts, ctx)
+	return s.inMemoryStore.AddItem(item, parent, level)
+}
+
+func (s *contextRecordingStore) LoadChildrenContext(ctx context.Context, parents ...**Point) ([]LevelsWithItems[*Point], error) {
+	s.contexts = append(s.contexts, ctx)
+	return s.inMemoryStore.LoadChildren(parents...)
+}
+
+func (s *contextRecordingStore) RemoveItemContext(ctx context.Context, item *Point, parent **Point, level int) error {
+	s.contexts = append(s.contexts, ctx)
+	return s.inMemoryStore.RemoveItem(item, parent, level)
+}
+
+func (s *contextRecordingStore) expectOnlyContext(t *testing.T, ctx context.Context) {
+	t.Helper()
+
+	if len(s.contexts) == 0 {
+		t.Errorf("Expected context-aware store operations to be used but there were none")
+	}
+	for i := range s.contexts {
+		if expected, actual := ctx, s.contexts[i]; expected != actual {
+			t.Errorf("Expected store operation %d to receive context %v but got %v", i, expected, actual)
+		}
+	}
+}
+
+type failingStore struct {
+	inMemoryStore[*Point]
+	err error
+}
+
+func (s *failingStore) LoadChildren(parents ...**Point) ([]LevelsWithItems[*Point], error) {
+	return nil, s.err
+}
+
+func insertPoints(points []Point, tree *Tree[*Point]) (timeTaken time.Duration, err error) {
+	startTime := time.Now()
+
+	for i := range points {
+		err := tree.Insert(&points[i])
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	finishTime := time.Now()
+
+	return finishTime.Sub(startTime), nil
+}
+
+func linearSearch(query *Point, points []Point, maxResults int, maxDistance float64) (results []ItemWithDistance[*Point], distanceCallCount int) {
+	distanceCalls := 0
+	distanceBetween := distanceBetweenPointsWithCounter(&distanceCalls)
+
+	startTime := time.Now()
+
+	lastNonNil := 0
+	results = make([]ItemWithDistance[*Point], maxResults)
+
+	for i := range points {
+		dist := distanceBetween(query, &points[i])
+		if dist > maxDistance {
+			continue
+		}
+
+		for j := 0; j < len(results); j++ {
+			if results[j].Item == nil || dist < results[j].Distance {
+				for k := len(results) - 1; k > j; k-- {
+					results[k] = results[k-1]
+				}
+				lastNonNil++
+				results[j].Item = &points[i]
+				results[j].Distance = dist
+				break
+			}
+		}
+	}
+
+	if lastNonNil > len(results) {
+		lastNonNil = len(results)
+	}
+	results = results[:lastNonNil]
+
+	finishTime := time.Now()
+
+	linearSearchDistanceCalls := distanceCalls
+
+	fmt.Printf("Linear FindNearest took %d distance comparisons, %dms\n", linearSearchDistanceCalls, finishTime.Sub(startTime)/time.Millisecond)
+
+	for _, r := range results {
+		fmt.Printf("Linear FindNearest: %v (distance %g)\n", *r.Item, r.Distance)
+	}
+
+	return results, linearSearchDistanceCalls
+}
+
+func loadRoot(tree *Tree[*Point]) (root *Point, rootLevel int, err error) {
+	rootLevels, err := tree.NewTracer().loadChildren(nil)
+	if err != nil {
+		return
+	}
+
+	for level, items := range rootLevels[0].items {
+		root = items[0]
+		rootLevel = level
+		break
+	}
+
+	return
+}
+
+func randomPoint() (point Point) {
+	for i := 0; i < len(point); i++ {
+		point[i] = rand.Float64() * 1000
+	}
+	return point
+}
+
+func randomPoints(count int) (points []Point) {
+	pointsMap := make(map[Point]bool, count)
+	for len(pointsMap) < count {
+		val := randomPoint()
+		if _, exists := pointsMap[val]; !exists {
+			pointsMap[val] = true
+			points = append(points, val)
+		}
+	}
+
+	return
+}
+
+func traverseNodes(item, parent *Point, level int, indentLevel int, store *inMemoryStore[*Point], print bool) (nodeCount int) {
+	if print {
+		fmt.Printf("%4d: ", level)
+		for i := 0; i < indentLevel; i++ {
+			fmt.Print("..")
+		}
+		if indentLevel > 0 {
+			fmt.Print(" ")
+		}
+
+		fmt.Printf("%v", item)
+
+		if parent != nil {
+			fmt.Printf(" (%g)", distanceBetweenPoints(item, parent))
+		}
+
+		fmt.Println()
+	}
+
+	nodeCount = 1
+
+	var levels []int
+	for k := range store.levelsFor(item) {
+		levels = append(levels, k)
+	}
+	sort.Ints(levels)
+
+	for i := len(levels) - 1; i >= 0; i-- {
+		l := levels[i]
+		children, _ := store.LoadChildren(&item)
+		for _, c := range children[0].itemsAt(l) {
+			nodeCount += traverseNodes(c, item, l, indentLevel+1, store, print)
+		}
+	}
+
+	return
+}
+
+func traverseTree(tree *Tree[*Point], store *inMemoryStore[*Point], print bool) (nodeCount int) {
+	if print {
+		fmt.Println("---")
+	}
+
+	roots, _ := tree.NewTracer().loadChildren(nil)
+
+	for _, root := range roots {
+		for _, rootNode := range root.itemsAt(tree.rootLevel) {
+			nodeCount += traverseNodes(rootNode, nil, tree.rootLevel, 0, store, print)
+		}
+	}
+	return
+}
+
+type testStore struct {
+	inMemoryStore[*Point]
+	savedCount int
+	savedRoots []*Point
+}
+
+func newTestStore(distanceFunc DistanceFunc[*Point]) *testStore {
+	return &testStore{inMemoryStore: *NewInMemoryStore(distanceFunc)}
+}
+
+func (ts *testStore) AddItem(item *Point, parent **Point, level int) error {
+	ts.savedCount++
+
+	if parent == nil {
+		ts.savedRoots = append(ts.savedRoots, item)
+	}
+	return ts.inMemoryStore.AddItem(item, parent, level)
+}
+
+func (ts *testStore) RemoveItem(item *Point, parent **Point, level int) error {
+	ts.savedCount++
+
+	if parent == nil {
+		for i := range ts.savedRoots {
+			if item == ts.savedRoots[i] {
+				ts.savedRoots = append(ts.savedRoots[:i], ts.savedRoots[i+1:]...)
+				break
+			}
+		}
+	}
+	return ts.inMemoryStore.RemoveItem(item, parent, level)
+}
+
+func (ts *testStore) UpdateItem(item *Point, parent **Point, level int) error {
+	ts.savedCount++
+
+	if parent == nil {
+		found := false
+		for i := range ts.savedRoots {
+			if item == ts.savedRoots[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ts.savedRoots = append(ts.savedRoots, item)
+		}
+	}
+	return ts.inMemoryStore.UpdateItem(item, parent, level)
+}
+
+func (ts *testStore) expectSavedTree(t *testing.T, saveCount int, roots []*Point, rootLevel int) {
+	t.Helper()
+
+	if expected, actual := saveCount, ts.savedCount; expected != actual {
+		t.Errorf("Expected tree to have been saved %d times but was saved %d times instead", expected, actual)
+	}
+	if expected, actual := len(roots), len(ts.savedRoots); expected != actual {
+		t.Errorf("Expected tree to have %d roots but got %d", expected, actual)
+	} else {
+		for i := range roots {
+			if expected, actual := roots[i], ts.savedRoots[i]; expected != actual {
+				t.Errorf("Expected tree root %v but was %v", expected, actual)
+			}
+		}
+	}
+}
+
+// sliceStore is a Store for items which cannot be compared, identifying items
+// by their formatted values. Items with equal values are kept as separate items
+// but share their children, so only leaves should be duplicated.
+type sliceStore struct {
+	items map[string]map[int][][]float64
+}
+
+func newSliceTree(items ...[]float64) (*Tree[[]float64], error) {
+	distanceBetween := func(a, b []float64) float64 {
+		return math.Abs(a[0] - b[0])
+	}
+
+	tree, err := NewTreeWithStore[[]float64](&sliceStore{items: make(map[string]map[int][][]float64)}, 2, 1000.0, distanceBetween)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		err = tree.Insert(item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tree, nil
+}
+
+func (s *sliceStore) AddItem(item []float64, parent *[]float64, level int) error {
+	levels, ok := s.items[sliceStoreKey(parent)]
+	if !ok {
+		levels = make(map[int][][]float64)
+		s.items[sliceStoreKey(parent)] = levels
+	}
+
+	levels[level] = append(levels[level], item)
+	return nil
+}
+
+func (s *sliceStore) LoadChildren(parents ...*[]float64) ([]LevelsWithItems[[]float64], error) {
+	results := make([]LevelsWithItems[[]float64], len(parents))
+	for i := range parents {
+		for level, items := range s.items[sliceStoreKey(parents[i])] {
+			results[i].Set(level, items)
+		}
+	}
+
+	return results, nil
+}
+
+func (s *sliceStore) RemoveItem(item []float64, parent *[]float64, level int) error {
+	levels := s.items[sliceStoreKey(parent)]
+	for i, levelItem := range levels[level] {
+		if fmt.Sprint(levelItem) == fmt.Sprint(item) {
+			levels[level] = append(levels[level][:i], levels[level][i+1:]...)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (s *sliceStore) UpdateItem(item []float64, parent *[]float64, level int) error {
+	if parent == nil {
+		for level := range s.items[""] {
+			_ = s.RemoveItem(item, nil, level)
+		}
+	}
+
+	return s.AddItem(item, parent, level)
+}
+
+func sliceStoreKey(parent *[]float64) string {
+	if parent == nil {
+		return ""
+	}
+	return fmt.Sprint(*parent)
+}
+
+type Point [3]float64
+
+func (p *Point) String() string {
+	return fmt.Sprintf("[%g %g %g]", p[0], p[1], p[2])
+}
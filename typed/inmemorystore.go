@@ -0,0 +1,168 @@
+package typed
+
+import (
+	"sync"
+)
+
+// inMemoryStore keeps the parent of each item and the size of its subtree
+// alongside its children, updating the sizes of an item’s ancestors as items
+// are added and removed so that LoadSubtreeSizes need not visit the subtrees.
+//
+// Items are used as map keys, with nil standing for the root of the tree, so
+// items which are equal are the same item. Adding an item which is already in
+// the store has no effect, as its children would otherwise be shared by each
+// copy of it.
+type inMemoryStore[T comparable] struct {
+	distanceBetween DistanceFunc[T]
+	items           map[interface{}]map[int][]T
+	parents         map[interface{}]interface{}
+	sizes           map[interface{}]int
+	mutex           sync.RWMutex
+}
+
+func NewInMemoryStore[T comparable](distanceFunc DistanceFunc[T]) *inMemoryStore[T] {
+	return &inMemoryStore[T]{
+		distanceBetween: distanceFunc,
+		items:           make(map[interface{}]map[int][]T),
+		parents:         make(map[interface{}]interface{}),
+		sizes:           make(map[interface{}]int),
+	}
+}
+
+func (s *inMemoryStore[T]) AddItem(item T, parent *T, level int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.parents[item]; !ok {
+		s.addItem(item, parent, level)
+	}
+	return nil
+}
+
+func (s *inMemoryStore[T]) LoadChildren(parents ...*T) ([]LevelsWithItems[T], error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	results := make([]LevelsWithItems[T], len(parents))
+	for i := range parents {
+		for level, items := range s.items[keyForParent(parents[i])] {
+			results[i].Set(level, items)
+		}
+	}
+
+	return results, nil
+}
+
+func (s *inMemoryStore[T]) LoadSubtreeSizes(items ...T) ([]int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	sizes := make([]int, len(items))
+	for i := range items {
+		sizes[i] = s.subtreeSize(items[i])
+	}
+
+	return sizes, nil
+}
+
+func (s *inMemoryStore[T]) RemoveItem(item T, parent *T, level int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	levels := s.items[keyForParent(parent)]
+	for i, levelItem := range levels[level] {
+		if levelItem == item {
+			levels[level] = append(levels[level][:i], levels[level][i+1:]...)
+			delete(s.items, item)
+
+			s.addToAncestorSizes(keyForParent(parent), -s.subtreeSize(item))
+			delete(s.parents, item)
+			delete(s.sizes, item)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (s *inMemoryStore[T]) UpdateItem(item T, parent *T, level int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if parent == nil {
+		for level := range s.items[nil] {
+			for i := range s.items[nil][level] {
+				if s.items[nil][level][i] == item {
+					s.items[nil][level] = append(s.items[nil][level][:i], s.items[nil][level][i+1:]...)
+				}
+			}
+		}
+	}
+
+	s.addItem(item, parent, level)
+	return nil
+}
+
+func (s *inMemoryStore[T]) addItem(item T, parent *T, level int) {
+	levels := s.levelsFor(keyForParent(parent))
+	levels[level] = append(levels[level], item)
+
+	size := s.subtreeSize(item)
+	s.sizes[item] = size
+	s.parents[item] = keyForParent(parent)
+	s.addToAncestorSizes(keyForParent(parent), size)
+}
+
+// addToAncestorSizes adjusts the subtree size of the specified parent and each
+// of its ancestors. No chain of ancestors can be longer than the number of
+// items, which bounds the walk if items have been stored beneath themselves.
+func (s *inMemoryStore[T]) addToAncestorSizes(parent interface{}, delta int) {
+	ancestor := parent
+	for steps := 0; ancestor != nil && steps <= len(s.parents); steps++ {
+		if size, ok := s.sizes[ancestor]; ok {
+			s.sizes[ancestor] = size + delta
+		}
+		ancestor = s.parents[ancestor]
+	}
+}
+
+func (s *inMemoryStore[T]) levelsFor(key interface{}) map[int][]T {
+	levels, ok := s.items[key]
+	if !ok {
+		levels = make(map[int][]T)
+		s.items[key] = levels
+	}
+
+	return levels
+}
+
+func (s *inMemoryStore[T]) subtreeSize(item T) int {
+	if size, ok := s.sizes[item]; ok {
+		return size
+	}
+	return 1
+}
+
+// NewInMemoryTree creates a new, empty tree which is backed by an in-memory
+// store. The tree will use the specified function for determining the distance
+// between items.
+//
+// Note that for the sake of efficiency, and due to how an in-memory tree will
+// tend to be used, the in-memory implementation uses equality instead of
+// distance-identity. In particular, this means that removal requires the exact
+// item to be specified in order to be removed, and that inserting an item equal
+// to one already in the tree has no effect. Items which should be kept distinct
+// despite having equal values can be inserted by pointer.
+func NewInMemoryTree[T comparable](basis float64, rootDistance float64, distanceFunc DistanceFunc[T]) *Tree[T] {
+	tree, _ := NewTreeWithStore[T](NewInMemoryStore(distanceFunc), basis, rootDistance, distanceFunc)
+	return tree
+}
+
+// keyForParent returns the key under which the children of the specified
+// parent are kept, which is nil for the root of the tree.
+func keyForParent[T comparable](parent *T) interface{} {
+	if parent == nil {
+		return nil
+	}
+	return *parent
+}
@@ -0,0 +1,290 @@
+package typed
+
+import (
+	"math"
+	"testing"
+)
+
+type dummyItem struct {
+	id    string
+	value float64
+}
+
+func TestInMemoryStore(t *testing.T) {
+
+	distanceBetween := func(a, b *dummyItem) float64 {
+		return math.Abs(a.value - b.value)
+	}
+
+	t.Run("AddItem()", func(t *testing.T) {
+
+		t.Run("adds an entry for a new child item", func(t *testing.T) {
+			item := &dummyItem{"child", 123.0}
+			parent := &dummyItem{"parent", 456.0}
+
+			s := NewInMemoryStore[*dummyItem](nil)
+			_ = s.AddItem(item, &parent, 5)
+
+			levels, ok := s.items[parent]
+			if !ok {
+				t.Fatalf("Expected levels to exist for parent %v", parent)
+			}
+			if actual, expected := len(levels[5]), 1; actual != expected {
+				t.Errorf("Expected 1 item in level but found %d", actual)
+			}
+			if actual, expected := levels[5][0], item; actual != expected {
+				t.Errorf("Expected item %f but found %f", expected.value, actual.value)
+			}
+		})
+	})
+
+	t.Run("LoadChildren()", func(t *testing.T) {
+		parent := &dummyItem{"parent", 456.0}
+		item1 := &dummyItem{"thing1", 123.0}
+		item2 := &dummyItem{"thing2", 234.0}
+
+		s := inMemoryStore[*dummyItem]{
+			items: map[interface{}]map[int][]*dummyItem{
+				parent: {
+					7: {item1, item2},
+				},
+			},
+		}
+
+		t.Run("retrieves existing child items", func(t *testing.T) {
+			allChildren, _ := s.LoadChildren(&parent)
+			items := allChildren[0].itemsAt(7)
+
+			if actual, expected := len(items), 2; actual != expected {
+				t.Errorf("Expected %d items but found %d", expected, actual)
+			} else {
+				if actual, expected := items[0], item1; actual != expected {
+					t.Errorf("Expected item '%s' but found %v", expected.id, actual)
+				}
+				if actual, expected := items[1], item2; actual != expected {
+					t.Errorf("Expected item '%s' but found %v", expected.id, actual)
+				}
+			}
+		})
+
+		t.Run("returns empty results for non-existent parent", func(t *testing.T) {
+			badParent := &dummyItem{"bad parent", 456.0}
+
+			allChildren, _ := s.LoadChildren(&badParent)
+
+			if actual, expected := len(allChildren[0].items), 0; actual != expected {
+				t.Errorf("Expected %d items but found %d", expected, actual)
+			}
+		})
+
+		t.Run("returns empty results for non-existent children", func(t *testing.T) {
+			parent := &dummyItem{"parent", 456.0}
+
+			allChildren, _ := s.LoadChildren(&parent)
+			items := allChildren[0].itemsAt(5)
+
+			if actual, expected := len(items), 0; actual != expected {
+				t.Errorf("Expected %d items but found %d", expected, actual)
+			}
+		})
+
+		t.Run("distinguishes the root from zero value items", func(t *testing.T) {
+			s := NewInMemoryStore[int](nil)
+			zero := 0
+
+			_ = s.AddItem(zero, nil, 1)
+			_ = s.AddItem(7, &zero, 0)
+
+			allChildren, _ := s.LoadChildren(nil, &zero)
+
+			if actual, expected := allChildren[0].itemsAt(1), []int{0}; len(actual) != 1 || actual[0] != expected[0] {
+				t.Errorf("Expected root items %v but found %v", expected, actual)
+			}
+			if actual, expected := allChildren[1].itemsAt(0), []int{7}; len(actual) != 1 || actual[0] != expected[0] {
+				t.Errorf("Expected child items %v but found %v", expected, actual)
+			}
+		})
+	})
+
+	t.Run("LoadSubtreeSizes()", func(t *testing.T) {
+		parent := &dummyItem{"parent", 456.0}
+		item1 := &dummyItem{"thing1", 123.0}
+		item2 := &dummyItem{"thing2", 234.0}
+		item3 := &dummyItem{"thing3", 345.0}
+
+		s := NewInMemoryStore[*dummyItem](nil)
+		_ = s.AddItem(parent, nil, 8)
+		_ = s.AddItem(item1, &parent, 7)
+		_ = s.AddItem(item2, &parent, 6)
+		_ = s.AddItem(item3, &item1, 5)
+
+		t.Run("returns the number of items in each subtree including the item itself", func(t *testing.T) {
+			sizes, err := s.LoadSubtreeSizes(parent, item1, item2)
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if expected, actual := 3, len(sizes); expected != actual {
+				t.Fatalf("Expected %d sizes but got %d", expected, actual)
+			}
+			for i, expected := range []int{4, 2, 1} {
+				if actual := sizes[i]; expected != actual {
+					t.Errorf("Expected subtree %d to have size %d but was %d", i, expected, actual)
+				}
+			}
+		})
+
+		t.Run("updates sizes when items are removed and their children reparented", func(t *testing.T) {
+			s := NewInMemoryStore[*dummyItem](nil)
+			_ = s.AddItem(parent, nil, 8)
+			_ = s.AddItem(item1, &parent, 7)
+			_ = s.AddItem(item2, &parent, 6)
+			_ = s.AddItem(item3, &item1, 5)
+
+			_ = s.RemoveItem(item1, &parent, 7)
+
+			sizes, _ := s.LoadSubtreeSizes(parent)
+			if expected, actual := 2, sizes[0]; expected != actual {
+				t.Errorf("Expected subtree to have size %d after removal but was %d", expected, actual)
+			}
+
+			_ = s.UpdateItem(item3, &item2, 5)
+
+			sizes, _ = s.LoadSubtreeSizes(parent, item2)
+			for i, expected := range []int{3, 2} {
+				if actual := sizes[i]; expected != actual {
+					t.Errorf("Expected subtree %d to have size %d after reparenting but was %d", i, expected, actual)
+				}
+			}
+		})
+	})
+
+	t.Run("RemoveItem()", func(t *testing.T) {
+		parent := &dummyItem{"parent", 456.0}
+		item1 := &dummyItem{"thing1", 123.0}
+		item2 := &dummyItem{"thing2", 234.0}
+
+		setup := func() inMemoryStore[*dummyItem] {
+			return inMemoryStore[*dummyItem]{
+				distanceBetween: distanceBetween,
+				items: map[interface{}]map[int][]*dummyItem{
+					parent: {
+						7: {item1, item2},
+					},
+					item2: {},
+				},
+			}
+		}
+
+		t.Run("removes an existing child", func(t *testing.T) {
+			s := setup()
+			_ = s.RemoveItem(item2, &parent, 7)
+
+			items := s.levelsFor(parent)[7]
+
+			if expected, actual := 1, len(items); expected != actual {
+				t.Errorf("Expected one child item after deletion but got %d", actual)
+			}
+			if expected, actual := item1, items[0]; expected != actual {
+				t.Errorf("Expected remaining child to be %v but was %v", expected, actual)
+			}
+			if _, childEntriesExist := s.items[item2]; childEntriesExist {
+				t.Errorf("Expected child entries for removed item to be deleted")
+			}
+		})
+	})
+
+	t.Run("UpdateItem()", func(t *testing.T) {
+
+		t.Run("saves node at the root", func(t *testing.T) {
+			item := &dummyItem{"child", 123.0}
+
+			s := NewInMemoryStore[*dummyItem](nil)
+			_ = s.UpdateItem(item, nil, 5)
+
+			levels, ok := s.items[nil]
+			if !ok {
+				t.Fatalf("Expected levels to exist at root")
+			}
+			if actual, expected := len(levels[5]), 1; actual != expected {
+				t.Errorf("Expected 1 item in level but found %d", actual)
+			}
+			if actual, expected := levels[5][0], item; actual != expected {
+				t.Errorf("Expected item %f but found %f", expected.value, actual.value)
+			}
+
+			_ = s.UpdateItem(item, nil, 7)
+
+			levels, ok = s.items[nil]
+			if !ok {
+				t.Fatalf("Expected levels to exist at root")
+			}
+			if actual, expected := len(levels[5]), 0; actual != expected {
+				t.Errorf("Expected item to be gone from previous level but found %d", actual)
+			}
+			if actual, expected := len(levels[7]), 1; actual != expected {
+				t.Errorf("Expected 1 item in level but found %d", actual)
+			}
+			if actual, expected := levels[7][0], item; actual != expected {
+				t.Errorf("Expected item %f but found %f", expected.value, actual.value)
+			}
+		})
+
+		t.Run("adds item as a child of the parent", func(t *testing.T) {
+			item := &dummyItem{"child", 123.0}
+			parent := &dummyItem{"parent", 456.0}
+
+			s := NewInMemoryStore[*dummyItem](nil)
+			_ = s.UpdateItem(item, &parent, 5)
+
+			levels, ok := s.items[parent]
+			if !ok {
+				t.Fatalf("Expected levels to exist for parent %v", parent)
+			}
+			if actual, expected := len(levels[5]), 1; actual != expected {
+				t.Errorf("Expected 1 item in level but found %d", actual)
+			}
+			if actual, expected := levels[5][0], item; actual != expected {
+				t.Errorf("Expected item %f but found %f", expected.value, actual.value)
+			}
+		})
+	})
+
+	t.Run("NewInMemoryTree()", func(t *testing.T) {
+
+		t.Run("treats items with equal values as the same item", func(t *testing.T) {
+			distanceBetween := func(a, b [2]float64) float64 {
+				return math.Hypot(a[0]-b[0], a[1]-b[1])
+			}
+
+			tree := NewInMemoryTree[[2]float64](2, 1000.0, distanceBetween)
+			for _, item := range [][2]float64{{0, 0}, {0, 0}, {10, 10}, {20, 0}} {
+				_ = tree.Insert(item)
+			}
+
+			visits := make(map[[2]float64]int)
+			err := tree.Walk(func(item [2]float64, parent *[2]float64, level int) error {
+				visits[item]++
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+
+			for _, item := range [][2]float64{{0, 0}, {10, 10}, {20, 0}} {
+				if expected, actual := 1, visits[item]; expected != actual {
+					t.Errorf("Expected %v to be visited %d time but was visited %d times", item, expected, actual)
+				}
+			}
+			if expected, actual := 3, len(visits); expected != actual {
+				t.Errorf("Expected %d items to be visited but got %d", expected, actual)
+			}
+
+			count, _ := tree.CountWithin([2]float64{0, 0}, 100)
+			results, _ := tree.FindWithin([2]float64{0, 0}, 100)
+			if expected, actual := len(results), count; expected != actual {
+				t.Errorf("Expected count of %d items but got %d", expected, actual)
+			}
+		})
+	})
+}
@@ -0,0 +1,115 @@
+package typed
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mandykoh/go-parallel"
+)
+
+// InsertError describes the failure to insert an item into a tree.
+type InsertError[T any] struct {
+	Item T
+	Err  error
+}
+
+func (e *InsertError[T]) Error() string {
+	return fmt.Sprintf("failed to insert %v: %v", e.Item, e.Err)
+}
+
+// Unwrap returns the error which caused the insertion to fail.
+func (e *InsertError[T]) Unwrap() error {
+	return e.Err
+}
+
+// InsertErrors describes the failures to insert items into a tree by InsertAll
+// or InsertFrom, in no particular order.
+type InsertErrors[T any] []*InsertError[T]
+
+func (e InsertErrors[T]) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("failed to insert %d items; first error: %v", len(e), e[0])
+}
+
+// ProgressFunc represents a function which is notified of the progress of a
+// bulk operation, with the number of items which have been processed
+// successfully and the number which have failed so far.
+type ProgressFunc func(succeeded, failed int)
+
+// InsertAll inserts the specified items into the tree, using the specified
+// number of workers to make insertions concurrently. If workers is not
+// positive, runtime.GOMAXPROCS(0) workers are used.
+//
+// If progress is not nil, it is called after each item is inserted or fails
+// to be inserted. Calls to progress are never made concurrently.
+//
+// Items which fail to be inserted do not prevent the remaining items from
+// being inserted. If any items fail, an InsertErrors is returned describing
+// each failure along with the item.
+//
+// Multiple calls to InsertAll, FindNearest and Insert are safe to make
+// concurrently.
+func (t *Tree[T]) InsertAll(items []T, workers int, progress ProgressFunc) error {
+	var next int64 = -1
+
+	return t.insertWithWorkers(workers, progress, func() (item T, ok bool) {
+		i := atomic.AddInt64(&next, 1)
+		if i >= int64(len(items)) {
+			return item, false
+		}
+		return items[i], true
+	})
+}
+
+// InsertFrom inserts the items received from the specified channel into the
+// tree until it is closed, using the specified number of workers to make
+// insertions concurrently. If workers is not positive, runtime.GOMAXPROCS(0)
+// workers are used.
+//
+// Progress and failures are reported as for InsertAll.
+//
+// Multiple calls to InsertFrom, FindNearest and Insert are safe to make
+// concurrently.
+func (t *Tree[T]) InsertFrom(items <-chan T, workers int, progress ProgressFunc) error {
+	return t.insertWithWorkers(workers, progress, func() (item T, ok bool) {
+		item, ok = <-items
+		return
+	})
+}
+
+func (t *Tree[T]) insertWithWorkers(workers int, progress ProgressFunc, nextItem func() (item T, ok bool)) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var errs InsertErrors[T]
+	var succeeded int
+	var mutex sync.Mutex
+
+	parallel.RunWorkers(workers, func(workerNum, workerCount int) {
+		for item, ok := nextItem(); ok; item, ok = nextItem() {
+			err := t.Insert(item)
+
+			mutex.Lock()
+			if err != nil {
+				errs = append(errs, &InsertError[T]{item, err})
+			} else {
+				succeeded++
+			}
+			if progress != nil {
+				progress(succeeded, len(errs))
+			}
+			mutex.Unlock()
+		}
+	})
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
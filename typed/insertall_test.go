@@ -0,0 +1,172 @@
+package typed
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+type selectivelyFailingStore struct {
+	*inMemoryStore[*Point]
+	failingItems map[*Point]bool
+	err          error
+}
+
+func (s *selectivelyFailingStore) AddItem(item *Point, parent **Point, level int) error {
+	if s.failingItems[item] {
+		return s.err
+	}
+	return s.inMemoryStore.AddItem(item, parent, level)
+}
+
+func TestTreeInsertAll(t *testing.T) {
+
+	itemsFor := func(points []Point) (items []*Point) {
+		for i := range points {
+			items = append(items, &points[i])
+		}
+		return items
+	}
+
+	t.Run("inserts every item", func(t *testing.T) {
+		for _, workers := range []int{0, 1, 8} {
+			distanceCalls := 0
+			points := randomPoints(500)
+			store := NewInMemoryStore(distanceBetweenPoints)
+			tree, _ := NewTreeWithStore[*Point](store, 2, 1000.0, distanceBetweenPoints)
+
+			err := tree.InsertAll(itemsFor(points), workers, nil)
+
+			if err != nil {
+				t.Fatalf("Expected success with %d workers but got error: %v", workers, err)
+			}
+			if expected, actual := len(points), traverseTree(tree, store, false); expected != actual {
+				t.Errorf("Expected %d items in tree with %d workers but got %d", expected, workers, actual)
+			}
+
+			tree.distanceBetween = distanceBetweenPointsWithCounter(&distanceCalls)
+			compareWithLinearSearch(tree, points, 5, math.MaxFloat64, &distanceCalls, t)
+		}
+	})
+
+	t.Run("reports progress after each item", func(t *testing.T) {
+		points := randomPoints(100)
+		store := &selectivelyFailingStore{
+			inMemoryStore: NewInMemoryStore(distanceBetweenPoints),
+			failingItems:  map[*Point]bool{&points[10]: true, &points[20]: true},
+			err:           errors.New("store failure"),
+		}
+		tree, _ := NewTreeWithStore[*Point](store, 2, 1000.0, distanceBetweenPoints)
+
+		calls := 0
+		lastSucceeded, lastFailed := 0, 0
+
+		_ = tree.InsertAll(itemsFor(points), 4, func(succeeded, failed int) {
+			calls++
+			if succeeded+failed != calls {
+				t.Errorf("Expected %d items to have been processed but got %d succeeded and %d failed", calls, succeeded, failed)
+			}
+			if succeeded < lastSucceeded || failed < lastFailed {
+				t.Errorf("Expected progress not to go backwards but got %d/%d after %d/%d", succeeded, failed, lastSucceeded, lastFailed)
+			}
+			lastSucceeded, lastFailed = succeeded, failed
+		})
+
+		if expected, actual := len(points), calls; expected != actual {
+			t.Errorf("Expected progress to be reported %d times but got %d", expected, actual)
+		}
+		if expected, actual := len(points)-2, lastSucceeded; expected != actual {
+			t.Errorf("Expected %d items to have succeeded but got %d", expected, actual)
+		}
+		if expected, actual := 2, lastFailed; expected != actual {
+			t.Errorf("Expected %d items to have failed but got %d", expected, actual)
+		}
+	})
+
+	t.Run("returns failures with their items and inserts the remaining items", func(t *testing.T) {
+		storeErr := errors.New("store failure")
+		points := randomPoints(100)
+		failingItems := map[*Point]bool{&points[0]: true, &points[50]: true, &points[99]: true}
+		store := &selectivelyFailingStore{NewInMemoryStore(distanceBetweenPoints), failingItems, storeErr}
+		tree, _ := NewTreeWithStore[*Point](store, 2, 1000.0, distanceBetweenPoints)
+
+		err := tree.InsertAll(itemsFor(points), 4, nil)
+
+		var insertErrs InsertErrors[*Point]
+		if !errors.As(err, &insertErrs) {
+			t.Fatalf("Expected InsertErrors[*Point] but got %v", err)
+		}
+		if expected, actual := len(failingItems), len(insertErrs); expected != actual {
+			t.Fatalf("Expected %d failures but got %d", expected, actual)
+		}
+		for _, e := range insertErrs {
+			if !failingItems[e.Item] {
+				t.Errorf("Expected failure for one of the failing items but got %v", e.Item)
+			}
+			if !errors.Is(e, storeErr) {
+				t.Errorf("Expected error %v but got %v", storeErr, e.Err)
+			}
+		}
+		if expected, actual := len(points)-len(failingItems), traverseTree(tree, store.inMemoryStore, false); expected != actual {
+			t.Errorf("Expected %d items in tree but got %d", expected, actual)
+		}
+	})
+}
+
+func TestTreeInsertFrom(t *testing.T) {
+
+	t.Run("inserts every item received until the channel is closed", func(t *testing.T) {
+		points := randomPoints(500)
+		store := NewInMemoryStore(distanceBetweenPoints)
+		tree, _ := NewTreeWithStore[*Point](store, 2, 1000.0, distanceBetweenPoints)
+
+		items := make(chan *Point)
+		go func() {
+			for i := range points {
+				items <- &points[i]
+			}
+			close(items)
+		}()
+
+		calls := 0
+		err := tree.InsertFrom(items, 4, func(succeeded, failed int) {
+			calls++
+		})
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if expected, actual := len(points), traverseTree(tree, store, false); expected != actual {
+			t.Errorf("Expected %d items in tree but got %d", expected, actual)
+		}
+		if expected, actual := len(points), calls; expected != actual {
+			t.Errorf("Expected progress to be reported %d times but got %d", expected, actual)
+		}
+	})
+
+	t.Run("returns failures with their items", func(t *testing.T) {
+		storeErr := errors.New("store failure")
+		points := randomPoints(10)
+		store := &selectivelyFailingStore{NewInMemoryStore(distanceBetweenPoints), map[*Point]bool{&points[3]: true}, storeErr}
+		tree, _ := NewTreeWithStore[*Point](store, 2, 1000.0, distanceBetweenPoints)
+
+		items := make(chan *Point, len(points))
+		for i := range points {
+			items <- &points[i]
+		}
+		close(items)
+
+		err := tree.InsertFrom(items, 2, nil)
+
+		insertErrs, ok := err.(InsertErrors[*Point])
+		if !ok {
+			t.Fatalf("Expected InsertErrors[*Point] but got %v", err)
+		}
+		if expected, actual := 1, len(insertErrs); expected != actual {
+			t.Fatalf("Expected %d failures but got %d", expected, actual)
+		}
+		if expected, actual := &points[3], insertErrs[0].Item; expected != actual {
+			t.Errorf("Expected failure for %v but got %v", expected, actual)
+		}
+	})
+}
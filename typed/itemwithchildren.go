@@ -0,0 +1,28 @@
+package typed
+
+type itemWithChildren[T any] struct {
+	withDistance ItemWithDistance[T]
+	parent       *T
+	children     LevelsWithItems[T]
+
+	// id identifies the stored entry for the item within a batched search, and
+	// source identifies the entry it was promoted from, so that cover sets for
+	// different queries can share loaded children without comparing items.
+	id     int
+	source childSource
+}
+
+// childSource identifies a child by the id of its parent's entry and its
+// position amongst the parent's children at the same level.
+type childSource struct {
+	parentID int
+	position int
+}
+
+func (iwc *itemWithChildren[T]) hasChildren() bool {
+	return len(iwc.children.items) > 0
+}
+
+func (iwc *itemWithChildren[T]) takeChildrenAt(level int) []T {
+	return iwc.children.takeItemsAt(level)
+}
@@ -1,8 +1,8 @@
-package covertree
+package typed
 
 // ItemWithDistance represents an item and its distance from some other
 // predetermined item, as defined by a DistanceFunc.
-type ItemWithDistance struct {
-	Item     interface{}
+type ItemWithDistance[T any] struct {
+	Item     T
 	Distance float64
 }
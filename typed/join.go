@@ -0,0 +1,125 @@
+package typed
+
+// JoinWithin finds every pair of items, one from the left tree and one from the
+// right tree, which are within the specified distance of each other.
+//
+// Rather than searching one tree separately for each item of the other, the two
+// trees are traversed against each other, pruning pairs of nodes whose covering
+// distances are too far apart for any of their items to be within the distance.
+//
+// Both trees must use the same DistanceFunc, and distances are computed using
+// that of the left tree.
+//
+// found is called once for each pair with the item from the left tree, the item
+// from the right tree, and the distance between them. Pairs are reported in no
+// particular order. If found returns an error, the traversal stops and the
+// error is returned.
+//
+// Multiple calls to JoinWithin, FindNearest and Insert are safe to make
+// concurrently.
+func JoinWithin[T any](left, right *Tree[T], radius float64, found func(leftItem, rightItem T, distance float64) error) error {
+	j := &join[T]{
+		left:              left,
+		right:             right,
+		radius:            radius,
+		loadLeftChildren:  left.NewTracer().loadChildren,
+		loadRightChildren: right.NewTracer().loadChildren,
+		found:             found,
+	}
+
+	leftRoots, err := left.loadRootNodes(j.loadLeftChildren)
+	if err != nil {
+		return err
+	}
+
+	rightRoots, err := right.loadRootNodes(j.loadRightChildren)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range leftRoots {
+		for _, r := range rightRoots {
+			err = j.search(l, r, left.distanceBetween(l.item, r.item))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// join holds the state of a traversal of two trees against each other.
+type join[T any] struct {
+	left              *Tree[T]
+	right             *Tree[T]
+	radius            float64
+	loadLeftChildren  func(...*T) ([]LevelsWithItems[T], error)
+	loadRightChildren func(...*T) ([]LevelsWithItems[T], error)
+	found             func(T, T, float64) error
+}
+
+// search reports the pairs of items within the radius which are covered by the
+// left and right nodes, given the distance between the nodes’ items.
+func (j *join[T]) search(l, r *node[T], distance float64) error {
+	leftCover := j.left.coverDistanceForNode(l)
+	rightCover := j.right.coverDistanceForNode(r)
+
+	if distance-leftCover-rightCover > j.radius {
+		return nil
+	}
+
+	if l.isLeaf() && r.isLeaf() {
+		if distance <= j.radius {
+			return j.found(l.item, r.item, distance)
+		}
+		return nil
+	}
+
+	// Expand whichever node covers more, so that the pair is refined evenly
+	if r.isLeaf() || (!l.isLeaf() && leftCover >= rightCover) {
+		err := j.left.expandNodes([]*node[T]{l}, j.loadLeftChildren)
+		if err != nil {
+			return err
+		}
+
+		for i, child := range l.expanded {
+			childDistance := distance
+			if i > 0 {
+				if distance-j.left.parentDistanceForNode(child)-j.left.coverDistanceForNode(child)-rightCover > j.radius {
+					continue
+				}
+				childDistance = j.left.distanceBetween(child.item, r.item)
+			}
+
+			err = j.search(child, r, childDistance)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	err := j.right.expandNodes([]*node[T]{r}, j.loadRightChildren)
+	if err != nil {
+		return err
+	}
+
+	for i, child := range r.expanded {
+		childDistance := distance
+		if i > 0 {
+			if distance-j.right.parentDistanceForNode(child)-j.right.coverDistanceForNode(child)-leftCover > j.radius {
+				continue
+			}
+			childDistance = j.left.distanceBetween(l.item, child.item)
+		}
+
+		err = j.search(l, child, childDistance)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
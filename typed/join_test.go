@@ -0,0 +1,157 @@
+package typed
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJoinWithin(t *testing.T) {
+
+	type pair struct {
+		left  *Point
+		right *Point
+	}
+
+	t.Run("reports nothing when either tree is empty", func(t *testing.T) {
+		populated := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		_, _ = insertPoints(randomPoints(10), populated)
+
+		empty := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		for _, trees := range [][2]*Tree[*Point]{{populated, empty}, {empty, populated}} {
+			err := JoinWithin(trees[0], trees[1], 1000.0, func(leftItem, rightItem *Point, distance float64) error {
+				t.Errorf("Expected no pairs to be reported but got %v and %v", leftItem, rightItem)
+				return nil
+			})
+
+			if err != nil {
+				t.Errorf("Expected success but got error: %v", err)
+			}
+		}
+	})
+
+	t.Run("returns the same pairs as a linear search", func(t *testing.T) {
+		distanceCalls := 0
+		distanceFunc := distanceBetweenPointsWithCounter(&distanceCalls)
+
+		left := NewInMemoryTree(2, 1000.0, distanceFunc)
+		leftPoints := randomPoints(300)
+		_, _ = insertPoints(leftPoints, left)
+
+		right := NewInMemoryTree(2, 1000.0, distanceFunc)
+		rightPoints := randomPoints(300)
+		_, _ = insertPoints(rightPoints, right)
+
+		for _, radius := range []float64{0, 20, 60} {
+			distanceCalls = 0
+
+			reported := make(map[pair]float64)
+			err := JoinWithin(left, right, radius, func(leftItem, rightItem *Point, distance float64) error {
+				p := pair{leftItem, rightItem}
+				if _, ok := reported[p]; ok {
+					t.Errorf("Expected %v and %v to be reported only once", leftItem, rightItem)
+				}
+				reported[p] = distance
+				return nil
+			})
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if pairwise := len(leftPoints) * len(rightPoints); distanceCalls >= pairwise {
+				t.Errorf("Expected fewer than %d distance calls but made %d", pairwise, distanceCalls)
+			}
+
+			expectedCount := 0
+			for i := range leftPoints {
+				for j := range rightPoints {
+					expectedDistance := distanceBetweenPoints(&leftPoints[i], &rightPoints[j])
+					if expectedDistance > radius {
+						continue
+					}
+					expectedCount++
+
+					distance, ok := reported[pair{&leftPoints[i], &rightPoints[j]}]
+					if !ok {
+						t.Errorf("Expected %v and %v to be reported within %g", &leftPoints[i], &rightPoints[j], radius)
+					} else if distance != expectedDistance {
+						t.Errorf("Expected distance %g between %v and %v but got %g", expectedDistance, &leftPoints[i], &rightPoints[j], distance)
+					}
+				}
+			}
+
+			if expected, actual := expectedCount, len(reported); expected != actual {
+				t.Errorf("Expected %d pairs within %g but got %d", expected, radius, actual)
+			}
+		}
+	})
+
+	t.Run("includes pairs at zero distance", func(t *testing.T) {
+		left := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		leftPoints := []Point{{1, 2, 3}, {10, 0, 0}}
+		_, _ = insertPoints(leftPoints, left)
+
+		right := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		rightPoints := []Point{{1, 2, 3}, {1, 2, 3}}
+		_, _ = insertPoints(rightPoints, right)
+
+		reported := make(map[pair]float64)
+		err := JoinWithin(left, right, 0, func(leftItem, rightItem *Point, distance float64) error {
+			reported[pair{leftItem, rightItem}] = distance
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if expected, actual := 2, len(reported); expected != actual {
+			t.Fatalf("Expected %d pairs but got %d", expected, actual)
+		}
+		for i := range rightPoints {
+			if _, ok := reported[pair{&leftPoints[0], &rightPoints[i]}]; !ok {
+				t.Errorf("Expected %v and %v to be reported", &leftPoints[0], &rightPoints[i])
+			}
+		}
+	})
+
+	t.Run("stops when the callback returns an error", func(t *testing.T) {
+		left := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		_, _ = insertPoints(randomPoints(10), left)
+
+		right := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		_, _ = insertPoints(randomPoints(10), right)
+
+		stopErr := errors.New("stop")
+		reported := 0
+
+		err := JoinWithin(left, right, 1000.0, func(leftItem, rightItem *Point, distance float64) error {
+			reported++
+			return stopErr
+		})
+
+		if expected, actual := stopErr, err; expected != actual {
+			t.Errorf("Expected error %v but got %v", expected, actual)
+		}
+		if expected, actual := 1, reported; expected != actual {
+			t.Errorf("Expected %d pair to be reported but got %d", expected, actual)
+		}
+	})
+
+	t.Run("returns errors from either store", func(t *testing.T) {
+		storeErr := errors.New("store failure")
+		failing, _ := NewTreeWithStore[*Point](&failingStore{err: storeErr}, 2, 1000.0, distanceBetweenPoints)
+
+		populated := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		_, _ = insertPoints(randomPoints(10), populated)
+
+		for _, trees := range [][2]*Tree[*Point]{{failing, populated}, {populated, failing}} {
+			err := JoinWithin(trees[0], trees[1], 1000.0, func(leftItem, rightItem *Point, distance float64) error {
+				return nil
+			})
+
+			if expected, actual := storeErr, err; expected != actual {
+				t.Errorf("Expected error %v but got %v", expected, actual)
+			}
+		}
+	})
+}
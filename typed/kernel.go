@@ -0,0 +1,36 @@
+package typed
+
+import "math"
+
+// Kernel is a function which weights the contribution of an item to a density
+// estimate, given the item’s distance from the query and the bandwidth of the
+// estimate.
+//
+// Kernels must be non-increasing with distance, as this allows the
+// contributions of whole subtrees to be bounded by their nearest and furthest
+// possible distances.
+type Kernel func(distance, bandwidth float64) float64
+
+// EpanechnikovKernel weights items by one minus the square of their distance
+// relative to the bandwidth, such that items beyond the bandwidth contribute
+// nothing.
+func EpanechnikovKernel(distance, bandwidth float64) float64 {
+	u := distance / bandwidth
+	return math.Max(0, 1-u*u)
+}
+
+// GaussianKernel weights items according to a Gaussian function of their
+// distance, with the bandwidth as the standard deviation.
+func GaussianKernel(distance, bandwidth float64) float64 {
+	u := distance / bandwidth
+	return math.Exp(-u * u / 2)
+}
+
+// UniformKernel weights all items within the bandwidth equally, and items
+// beyond the bandwidth not at all.
+func UniformKernel(distance, bandwidth float64) float64 {
+	if distance <= bandwidth {
+		return 1
+	}
+	return 0
+}
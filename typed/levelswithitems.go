@@ -0,0 +1,68 @@
+package typed
+
+import "sort"
+
+// LevelsWithItems represents a set of child items of a parent item, separated
+// into their levels.
+type LevelsWithItems[T any] struct {
+	items map[int][]T
+}
+
+// Add adds an item to the specified level.
+func (lwi *LevelsWithItems[T]) Add(level int, item T) {
+	if lwi.items == nil {
+		lwi.items = make(map[int][]T)
+	}
+
+	lwi.items[level] = append(lwi.items[level], item)
+}
+
+// Set specifies the items for an entire level.
+func (lwi *LevelsWithItems[T]) Set(level int, items []T) {
+	if lwi.items == nil {
+		lwi.items = make(map[int][]T)
+	}
+
+	lwi.items[level] = items
+}
+
+func (lwi *LevelsWithItems[T]) clone() LevelsWithItems[T] {
+	var c LevelsWithItems[T]
+	for level, items := range lwi.items {
+		c.Set(level, items)
+	}
+
+	return c
+}
+
+func (lwi *LevelsWithItems[T]) itemsAt(level int) []T {
+	return lwi.items[level]
+}
+
+// levels returns the levels which have items, from highest to lowest.
+func (lwi *LevelsWithItems[T]) levels() []int {
+	levels := make([]int, 0, len(lwi.items))
+	for level, items := range lwi.items {
+		if len(items) > 0 {
+			levels = append(levels, level)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(levels)))
+
+	return levels
+}
+
+func (lwi *LevelsWithItems[T]) takeItemsAt(level int) []T {
+	items := lwi.items[level]
+	delete(lwi.items, level)
+	return items
+}
+
+func cloneLevelsWithItems[T any](lwis []LevelsWithItems[T]) []LevelsWithItems[T] {
+	clones := make([]LevelsWithItems[T], len(lwis))
+	for i := range lwis {
+		clones[i] = lwis[i].clone()
+	}
+
+	return clones
+}
@@ -0,0 +1,5 @@
+package typed
+
+// MatchFunc represents a function which determines whether an item should be
+// accepted as a result of a search.
+type MatchFunc[T any] func(item T) (matches bool)
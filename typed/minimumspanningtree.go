@@ -0,0 +1,333 @@
+package typed
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Edge represents an edge between two items in a tree, weighted by the
+// distance between them.
+type Edge[T any] struct {
+	From     T
+	To       T
+	Distance float64
+}
+
+// MinimumSpanningTree finds the minimum spanning tree of the items in the tree,
+// considering every pair of items to be connected by an edge weighted by the
+// distance between them.
+//
+// The spanning tree is found using Borůvka’s algorithm, where in each round the
+// shortest edge leaving every component is found by traversing the tree
+// against itself, pruning pairs of nodes which lie within the same component or
+// which are too far apart to contain a shorter edge.
+//
+// The edges of the spanning tree are returned in order from shortest to
+// longest. A tree with fewer than two items has no edges. An error is returned
+// if some items cannot be connected, such as when the distance between them is
+// infinite or NaN.
+//
+// The entire structure of the tree is loaded into memory for the duration of
+// the operation.
+func (t *Tree[T]) MinimumSpanningTree() ([]Edge[T], error) {
+	b, err := t.newBoruvka(t.NewTracer().loadChildren)
+	if err != nil {
+		return nil, err
+	}
+
+	var edges []Edge[T]
+
+	for components := len(b.items); components > 1; {
+		b.resetRound()
+
+		for _, q := range b.roots {
+			for _, r := range b.roots {
+				b.search(q, r, t.distanceBetween(q.item, r.item))
+			}
+		}
+
+		var shortest []candidateEdge
+		for component, e := range b.best {
+			if component == b.components.find(component) && e.from >= 0 {
+				shortest = append(shortest, e)
+			}
+		}
+
+		joined := false
+		for _, e := range shortest {
+			if b.components.union(e.from, e.to) {
+				edges = append(edges, Edge[T]{b.items[e.from], b.items[e.to], e.distance})
+				components--
+				joined = true
+			}
+		}
+
+		if !joined {
+			return nil, fmt.Errorf("no edges found between %d remaining components", components)
+		}
+	}
+
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].Distance < edges[j].Distance
+	})
+
+	return edges, nil
+}
+
+// boruvka holds the state of a minimum spanning tree search. Every item in the
+// tree is assigned an index, and components are tracked as sets of those
+// indices.
+//
+// best holds the shortest edge found leaving each component in the current
+// round, indexed by the component’s representative item. known holds the
+// shortest edge found from each item to an item in another component.
+type boruvka[T any] struct {
+	tree       *Tree[T]
+	roots      []*spanningNode[T]
+	nodes      []*spanningNode[T]
+	items      []T
+	components *unionFind
+	best       []candidateEdge
+	known      []candidateEdge
+}
+
+func (t *Tree[T]) newBoruvka(loadChildren func(...*T) ([]LevelsWithItems[T], error)) (*boruvka[T], error) {
+	b := &boruvka[T]{tree: t}
+
+	roots, err := t.loadRootNodes(loadChildren)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := roots
+	spanning := make([]*spanningNode[T], len(roots))
+	for i, root := range roots {
+		spanning[i] = b.newSpanningNode(root)
+	}
+	b.roots = spanning
+
+	for len(pending) > 0 {
+		err = t.expandNodes(pending, loadChildren)
+		if err != nil {
+			return nil, err
+		}
+
+		var nextPending []*node[T]
+		var nextSpanning []*spanningNode[T]
+
+		for i, n := range pending {
+			for _, child := range n.expanded {
+				sc := b.newSpanningNode(child)
+				spanning[i].children = append(spanning[i].children, sc)
+
+				if !child.isLeaf() {
+					nextPending = append(nextPending, child)
+					nextSpanning = append(nextSpanning, sc)
+				}
+			}
+		}
+
+		pending = nextPending
+		spanning = nextSpanning
+	}
+
+	b.components = newUnionFind(len(b.items))
+	b.best = make([]candidateEdge, len(b.items))
+	b.known = make([]candidateEdge, len(b.items))
+	for i := range b.known {
+		b.known[i] = candidateEdge{from: -1, to: -1, distance: math.Inf(1)}
+	}
+
+	return b, nil
+}
+
+// boundFor returns an upper limit on the length of the shortest edge leaving
+// the components of the items covered by the node.
+func (b *boruvka[T]) boundFor(n *spanningNode[T]) float64 {
+	if n.component >= 0 {
+		return math.Min(n.bound, b.best[n.component].distance)
+	}
+	return n.bound
+}
+
+func (b *boruvka[T]) consider(from, to int, distance float64) {
+	candidate := candidateEdge{from, to, distance}
+	if component := b.components.find(from); candidate.shorterThan(b.best[component]) {
+		b.best[component] = candidate
+	}
+	if candidate.shorterThan(b.known[from]) {
+		b.known[from] = candidate
+	}
+}
+
+func (b *boruvka[T]) newSpanningNode(n *node[T]) *spanningNode[T] {
+	sn := &spanningNode[T]{item: n.item, index: -1, parentDistance: b.tree.parentDistanceForNode(n), cover: b.tree.coverDistanceForNode(n)}
+
+	if n.isLeaf() {
+		sn.index = len(b.items)
+		b.items = append(b.items, n.item)
+	}
+
+	b.nodes = append(b.nodes, sn)
+	return sn
+}
+
+// resetRound prepares for a new round of the search by determining the
+// components of every node and clearing the shortest edges found.
+//
+// The shortest edges found from each item in previous rounds which still lead
+// to other components are used as the starting point for the new round, as
+// they limit how far the search needs to look.
+func (b *boruvka[T]) resetRound() {
+	for i := range b.best {
+		b.best[i] = candidateEdge{from: -1, to: -1, distance: math.Inf(1)}
+	}
+
+	for i, e := range b.known {
+		if e.to < 0 {
+			continue
+		}
+		if b.components.find(e.to) == b.components.find(i) {
+			b.known[i] = candidateEdge{from: -1, to: -1, distance: math.Inf(1)}
+			continue
+		}
+		if component := b.components.find(i); e.shorterThan(b.best[component]) {
+			b.best[component] = e
+		}
+	}
+
+	// Nodes are recorded before their children, so visiting them in reverse
+	// determines the components of children before those of their parents
+	for i := len(b.nodes) - 1; i >= 0; i-- {
+		n := b.nodes[i]
+
+		if n.isLeaf() {
+			n.component = b.components.find(n.index)
+			n.bound = b.best[n.component].distance
+			continue
+		}
+
+		n.component = n.children[0].component
+		n.bound = n.children[0].bound
+		for _, child := range n.children[1:] {
+			if child.component != n.component {
+				n.component = -1
+			}
+			n.bound = math.Max(n.bound, child.bound)
+		}
+	}
+}
+
+// search looks for the shortest edges leaving the components of the items
+// covered by the query node which lead to items covered by the reference node.
+func (b *boruvka[T]) search(q, r *spanningNode[T], distance float64) {
+	if q.component >= 0 && q.component == r.component {
+		return
+	}
+
+	if distance-q.cover-r.cover > b.boundFor(q) {
+		return
+	}
+
+	if q.isLeaf() && r.isLeaf() {
+		b.consider(q.index, r.index, distance)
+		q.bound = b.best[q.component].distance
+		return
+	}
+
+	if r.isLeaf() || (!q.isLeaf() && q.cover >= r.cover) {
+		childBound := 0.0
+
+		for i, child := range q.children {
+			childDistance := distance
+			if i > 0 {
+				if distance-child.parentDistance-child.cover-r.cover > b.boundFor(child) {
+					childBound = math.Max(childBound, child.bound)
+					continue
+				}
+				childDistance = b.tree.distanceBetween(child.item, r.item)
+			}
+
+			b.search(child, r, childDistance)
+			childBound = math.Max(childBound, child.bound)
+		}
+
+		q.bound = math.Min(q.bound, childBound)
+		return
+	}
+
+	distances := make([]float64, len(r.children))
+	order := make([]int, 0, len(r.children))
+	for i, child := range r.children {
+		distances[i] = distance
+		if i > 0 {
+			if distance-child.parentDistance-q.cover-child.cover > b.boundFor(q) {
+				continue
+			}
+			distances[i] = b.tree.distanceBetween(q.item, child.item)
+		}
+		order = append(order, i)
+	}
+
+	// Searching closer reference nodes first finds shorter edges sooner,
+	// allowing more of the remaining nodes to be pruned
+	sort.SliceStable(order, func(i, j int) bool {
+		return distances[order[i]] < distances[order[j]]
+	})
+
+	for _, i := range order {
+		b.search(q, r.children[i], distances[i])
+	}
+}
+
+// candidateEdge represents the shortest edge found so far which leaves a
+// component. Edges of equal length are ordered by the indices of their items,
+// so that every component agrees on which of them is shortest.
+type candidateEdge struct {
+	from     int
+	to       int
+	distance float64
+}
+
+func (e candidateEdge) shorterThan(other candidateEdge) bool {
+	if e.distance != other.distance {
+		return e.distance < other.distance
+	}
+
+	lo, hi := e.from, e.to
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	otherLo, otherHi := other.from, other.to
+	if otherLo > otherHi {
+		otherLo, otherHi = otherHi, otherLo
+	}
+
+	if lo != otherLo {
+		return lo < otherLo
+	}
+	return hi < otherHi
+}
+
+// spanningNode represents a fully expanded node of a tree during a minimum
+// spanning tree search. Its children are those of the expanded node, so the
+// first of them shares its item.
+//
+// Leaves are assigned an item index. component is the component shared by all
+// items covered by the node, or -1 if they belong to different components.
+// bound is an upper limit on the length of the shortest edge leaving any of
+// those components.
+type spanningNode[T any] struct {
+	item           T
+	index          int
+	parentDistance float64
+	cover          float64
+	children       []*spanningNode[T]
+	component      int
+	bound          float64
+}
+
+func (n *spanningNode[T]) isLeaf() bool {
+	return len(n.children) == 0
+}
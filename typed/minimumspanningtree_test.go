@@ -0,0 +1,158 @@
+package typed
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMinimumSpanningTree(t *testing.T) {
+
+	primWeight := func(points []Point) float64 {
+		inTree := make([]bool, len(points))
+		distances := make([]float64, len(points))
+		for i := range distances {
+			distances[i] = math.Inf(1)
+		}
+		distances[0] = 0
+
+		total := 0.0
+		for range points {
+			next := -1
+			for i := range points {
+				if !inTree[i] && (next == -1 || distances[i] < distances[next]) {
+					next = i
+				}
+			}
+
+			inTree[next] = true
+			total += distances[next]
+
+			for i := range points {
+				if d := distanceBetweenPoints(&points[next], &points[i]); !inTree[i] && d < distances[i] {
+					distances[i] = d
+				}
+			}
+		}
+
+		return total
+	}
+
+	t.Run("returns no edges for an empty tree", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+		edges, err := tree.MinimumSpanningTree()
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if expected, actual := 0, len(edges); expected != actual {
+			t.Errorf("Expected %d edges but got %d", expected, actual)
+		}
+	})
+
+	t.Run("returns no edges for a tree with a single item", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		p := randomPoint()
+		_ = tree.Insert(&p)
+
+		edges, err := tree.MinimumSpanningTree()
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if expected, actual := 0, len(edges); expected != actual {
+			t.Errorf("Expected %d edges but got %d", expected, actual)
+		}
+	})
+
+	t.Run("returns an error when items cannot be connected", func(t *testing.T) {
+		distanceFunc := func(a, b *Point) float64 {
+			if a == b {
+				return 0
+			}
+			return math.Inf(1)
+		}
+		store := NewInMemoryStore(distanceFunc)
+		tree, _ := NewTreeWithStore[*Point](store, 2, 1000.0, distanceFunc)
+		p1, p2 := randomPoint(), randomPoint()
+		_ = store.AddItem(&p1, nil, tree.rootLevel)
+		_ = store.AddItem(&p2, nil, tree.rootLevel)
+
+		edges, err := tree.MinimumSpanningTree()
+
+		if err == nil {
+			t.Errorf("Expected an error but got none")
+		}
+		if edges != nil {
+			t.Errorf("Expected no edges but got %v", edges)
+		}
+	})
+
+	t.Run("returns errors from the store", func(t *testing.T) {
+		storeErr := errors.New("store failure")
+		tree, _ := NewTreeWithStore[*Point](&failingStore{err: storeErr}, 2, 1000.0, distanceBetweenPoints)
+
+		_, err := tree.MinimumSpanningTree()
+
+		if expected, actual := storeErr, err; expected != actual {
+			t.Errorf("Expected error %v but got %v", expected, actual)
+		}
+	})
+
+	t.Run("with a populated tree", func(t *testing.T) {
+		var distanceCalls int
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPointsWithCounter(&distanceCalls))
+
+		points := randomPoints(1000)
+		_, _ = insertPoints(points, tree)
+
+		distanceCalls = 0
+		edges, err := tree.MinimumSpanningTree()
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+
+		t.Run("connects every item", func(t *testing.T) {
+			if expected, actual := len(points)-1, len(edges); expected != actual {
+				t.Fatalf("Expected %d edges but got %d", expected, actual)
+			}
+
+			clusters := NewDendrogram(edges).Cut(math.Inf(1))
+			if expected, actual := 1, len(clusters); expected != actual {
+				t.Fatalf("Expected edges to form %d component but got %d", expected, actual)
+			}
+			if expected, actual := len(points), len(clusters[0]); expected != actual {
+				t.Errorf("Expected edges to connect %d items but got %d", expected, actual)
+			}
+		})
+
+		t.Run("has the minimum total weight", func(t *testing.T) {
+			total := 0.0
+			for _, e := range edges {
+				if expected, actual := distanceBetweenPoints(e.From, e.To), e.Distance; expected != actual {
+					t.Errorf("Expected edge distance %g but got %g", expected, actual)
+				}
+				total += e.Distance
+			}
+
+			if expected, actual := primWeight(points), total; math.Abs(expected-actual) > 1e-6 {
+				t.Errorf("Expected total weight %g but got %g", expected, actual)
+			}
+		})
+
+		t.Run("returns edges from shortest to longest", func(t *testing.T) {
+			for i := 1; i < len(edges); i++ {
+				if edges[i].Distance < edges[i-1].Distance {
+					t.Errorf("Expected edge %d with distance %g to come before edge %d with distance %g", i, edges[i].Distance, i-1, edges[i-1].Distance)
+				}
+			}
+		})
+
+		t.Run("makes fewer distance comparisons than a pairwise search", func(t *testing.T) {
+			if pairwise := len(points) * (len(points) - 1) / 2; distanceCalls >= pairwise {
+				t.Errorf("Expected fewer than %d distance calls but made %d", pairwise, distanceCalls)
+			}
+		})
+	})
+}
@@ -0,0 +1,92 @@
+package typed
+
+import (
+	"math"
+)
+
+// NearestIterator iterates over the items of a Tree in order of increasing
+// distance from a query item.
+//
+// NearestIterators can be created using the tree’s Nearest method. Children
+// are loaded from the tree’s Store only when they might contain the next
+// result.
+//
+// NearestIterators are not thread safe and should not be shared by multiple
+// Goroutines.
+type NearestIterator[T any] struct {
+	tree    *Tree[T]
+	query   T
+	tracer  *Tracer[T]
+	queue   priorityQueue[nodeWithDistance[T]]
+	started bool
+	result  ItemWithDistance[T]
+	err     error
+}
+
+func newNearestIterator[T any](tree *Tree[T], query T, tracer *Tracer[T]) *NearestIterator[T] {
+	return &NearestIterator[T]{
+		tree:   tree,
+		query:  query,
+		tracer: tracer,
+	}
+}
+
+// Err returns the first error encountered during iteration, if any.
+func (it *NearestIterator[T]) Err() error {
+	return it.err
+}
+
+// Next advances the iterator to the next closest item, which is then available
+// via Result. Next returns false when there are no more items or an error has
+// occurred.
+func (it *NearestIterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if !it.started {
+		it.started = true
+
+		roots, err := it.tree.loadRootNodes(it.tracer.loadChildren)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		for _, root := range it.tree.nodesWithDistance(roots, it.query) {
+			it.push(root)
+		}
+	}
+
+	for it.queue.Len() > 0 {
+		n, _ := it.queue.pop()
+
+		if n.isLeaf() {
+			it.result = ItemWithDistance[T]{n.node.item, n.distance}
+			return true
+		}
+
+		children, err := it.tree.expandNodesWithDistance([]nodeWithDistance[T]{n}, it.query, it.tracer.loadChildren)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		for _, child := range children {
+			it.push(child)
+		}
+	}
+
+	return false
+}
+
+// Result returns the item found by the most recent call to Next, along with its
+// distance from the query item. The zero ItemWithDistance is returned if Next
+// has not found any items.
+func (it *NearestIterator[T]) Result() ItemWithDistance[T] {
+	return it.result
+}
+
+func (it *NearestIterator[T]) push(n nodeWithDistance[T]) {
+	it.queue.push(n, math.Max(0, n.distance-it.tree.coverDistanceForNode(n.node)))
+}
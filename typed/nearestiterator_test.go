@@ -0,0 +1,118 @@
+package typed
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNearestIterator(t *testing.T) {
+
+	t.Run("Next()", func(t *testing.T) {
+
+		t.Run("returns no results for empty tree", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			query := randomPoint()
+			it := tree.Nearest(&query)
+
+			if it.Next() {
+				t.Errorf("Expected no results but got %v", it.Result())
+			}
+			if err := it.Err(); err != nil {
+				t.Errorf("Expected no error but got %v", err)
+			}
+		})
+
+		t.Run("returns all items in order of distance", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			points := randomPoints(500)
+			_, _ = insertPoints(points, tree)
+
+			for i := 0; i < 10; i++ {
+				query := randomPoint()
+				it := tree.Nearest(&query)
+
+				var results []ItemWithDistance[*Point]
+				for it.Next() {
+					results = append(results, it.Result())
+				}
+				if err := it.Err(); err != nil {
+					t.Fatalf("Expected iteration to succeed but got error: %v", err)
+				}
+
+				expectedResults, _ := linearSearch(&query, points, len(points), math.MaxFloat64)
+				expectSameResults(t, query, results, expectedResults)
+			}
+		})
+
+		t.Run("returns the same results as FindNearest when stopped early", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			points := randomPoints(1000)
+			_, _ = insertPoints(points, tree)
+
+			query := randomPoint()
+			it := tree.Nearest(&query)
+
+			var results []ItemWithDistance[*Point]
+			for len(results) < 20 && it.Next() {
+				results = append(results, it.Result())
+			}
+
+			expectedResults, _ := tree.FindNearest(&query, 20, math.MaxFloat64)
+			expectSameResults(t, query, results, expectedResults)
+		})
+
+		t.Run("loads children only as needed", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			points := randomPoints(1000)
+			_, _ = insertPoints(points, tree)
+
+			query := randomPoint()
+			it := tree.Nearest(&query)
+			it.Next()
+
+			if loadCount := it.tracer.LoadChildrenCount; loadCount >= len(points) {
+				t.Errorf("Expected fewer than %d LoadChildren calls for the first result but got %d", len(points), loadCount)
+			}
+		})
+
+		t.Run("stops and reports errors from the store", func(t *testing.T) {
+			storeErr := errors.New("store failure")
+			tree, _ := NewTreeWithStore[*Point](&failingStore{err: storeErr}, 2, 1000.0, distanceBetweenPoints)
+
+			query := randomPoint()
+			it := tree.Nearest(&query)
+
+			if it.Next() {
+				t.Errorf("Expected no results but got %v", it.Result())
+			}
+			if expected, actual := storeErr, it.Err(); expected != actual {
+				t.Errorf("Expected error %v but got %v", expected, actual)
+			}
+		})
+	})
+
+	t.Run("Result()", func(t *testing.T) {
+
+		t.Run("returns the zero result when no items have been found", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			query := randomPoint()
+			it := tree.Nearest(&query)
+
+			if result := it.Result(); result.Item != nil || result.Distance != 0 {
+				t.Errorf("Expected zero result before Next() but got %v", result)
+			}
+
+			it.Next()
+
+			if result := it.Result(); result.Item != nil || result.Distance != 0 {
+				t.Errorf("Expected zero result for empty tree but got %v", result)
+			}
+		})
+	})
+}
@@ -0,0 +1,170 @@
+package typed
+
+// node represents an item in a tree along with its explicit children, for
+// operations which traverse the structure of the tree directly rather than via
+// cover sets.
+//
+// parent is the node which was expanded to obtain the node, if any. The
+// distance of the node’s item from its parent’s item is computed on demand by
+// parentDistanceForNode and kept in parentDistance (which is negative until
+// then). This allows distances to children to be bounded using the triangle
+// inequality before computing them, without every traversal having to pay for
+// it.
+//
+// Expanding a node yields the node’s own item as a leaf (representing the item
+// itself) followed by a node for each of its children, so that every item in
+// the subtree is reachable exactly once as a leaf. As the first expanded node
+// shares the node’s item, its distance from anything is the node’s own, and
+// traversals reuse the node’s distance for it rather than computing it again.
+type node[T any] struct {
+	item           T
+	parent         *node[T]
+	parentDistance float64
+	children       LevelsWithItems[T]
+	childLevels    []int
+	expanded       []*node[T]
+}
+
+func (n *node[T]) isLeaf() bool {
+	return len(n.childLevels) == 0
+}
+
+// coverDistanceForNode returns the maximum distance between the node’s item
+// and any item in its subtree.
+//
+// This is tighter than the geometric sum of the distances permitted between
+// each parent and child, because items only ever enter a subtree through its
+// root. Insert only promotes the children of items which are visible from the
+// item being inserted, so anything inserted beneath a child at level c must
+// have been within distanceForLevel(c+1) of the node when that child was
+// promoted. Cover set searches rely on the same property when they discard
+// items beyond distanceForLevel(level) of the query.
+func (t *Tree[T]) coverDistanceForNode(n *node[T]) float64 {
+	if n.isLeaf() {
+		return 0
+	}
+	return t.distanceForLevel(n.childLevels[0] + 1)
+}
+
+func (t *Tree[T]) expandNodes(nodes []*node[T], loadChildren func(...*T) ([]LevelsWithItems[T], error)) error {
+	var toExpand []*node[T]
+	var childItems []T
+
+	for _, n := range nodes {
+		if n.isLeaf() || n.expanded != nil {
+			continue
+		}
+
+		toExpand = append(toExpand, n)
+		for _, level := range n.childLevels {
+			childItems = append(childItems, n.children.itemsAt(level)...)
+		}
+	}
+
+	if len(toExpand) == 0 {
+		return nil
+	}
+
+	children, err := t.loadNodes(childItems, loadChildren)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range toExpand {
+		childCount := 0
+		for _, level := range n.childLevels {
+			childCount += len(n.children.itemsAt(level))
+		}
+
+		n.expanded = append([]*node[T]{{item: n.item}}, children[:childCount]...)
+		children = children[childCount:]
+
+		for _, child := range n.expanded[1:] {
+			child.parent = n
+			child.parentDistance = -1
+		}
+	}
+
+	return nil
+}
+
+// expandNodesWithDistance expands the specified nodes, returning their
+// expanded children along with their distances from the query.
+func (t *Tree[T]) expandNodesWithDistance(nodes []nodeWithDistance[T], query T, loadChildren func(...*T) ([]LevelsWithItems[T], error)) ([]nodeWithDistance[T], error) {
+	toExpand := make([]*node[T], len(nodes))
+	for i := range nodes {
+		toExpand[i] = nodes[i].node
+	}
+
+	err := t.expandNodes(toExpand, loadChildren)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []nodeWithDistance[T]
+	for _, n := range nodes {
+		if n.node.isLeaf() {
+			continue
+		}
+
+		results = append(results, nodeWithDistance[T]{n.node.expanded[0], n.distance})
+		results = append(results, t.nodesWithDistance(n.node.expanded[1:], query)...)
+	}
+
+	return results, nil
+}
+
+func (t *Tree[T]) loadNodes(items []T, loadChildren func(...*T) ([]LevelsWithItems[T], error)) ([]*node[T], error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	children, err := loadChildren(parentsFor(items)...)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*node[T], len(items))
+	for i, item := range items {
+		nodes[i] = &node[T]{item: item, children: children[i], childLevels: children[i].levels()}
+	}
+
+	return nodes, nil
+}
+
+func (t *Tree[T]) loadRootNodes(loadChildren func(...*T) ([]LevelsWithItems[T], error)) ([]*node[T], error) {
+	roots, err := loadChildren(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.loadNodes(roots[0].itemsAt(t.rootLevel), loadChildren)
+}
+
+func (t *Tree[T]) parentDistanceForNode(n *node[T]) float64 {
+	if n.parent == nil {
+		return 0
+	}
+	if n.parentDistance < 0 {
+		n.parentDistance = t.distanceBetween(n.item, n.parent.item)
+	}
+	return n.parentDistance
+}
+
+type nodeWithDistance[T any] struct {
+	node     *node[T]
+	distance float64
+}
+
+func (n nodeWithDistance[T]) isLeaf() bool {
+	return n.node.isLeaf()
+}
+
+func (t *Tree[T]) nodesWithDistance(nodes []*node[T], query T) []nodeWithDistance[T] {
+	results := make([]nodeWithDistance[T], len(nodes))
+	for i, n := range nodes {
+		results[i] = nodeWithDistance[T]{n, t.distanceBetween(n.item, query)}
+	}
+
+	return results
+}
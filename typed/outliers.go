@@ -0,0 +1,212 @@
+package typed
+
+import "math"
+
+// KDistance returns the distance from the query to its kth nearest item in the
+// tree. An item at zero distance from the query is taken to be the query
+// itself, and is not considered to be one of its own nearest items, though any
+// further items at zero distance (duplicates) are. If there are fewer than k
+// other items, the distance to the furthest of them is returned, or zero if
+// there are none.
+func (t *Tree[T]) KDistance(query T, k int) (float64, error) {
+	neighbours, err := t.outlierNeighbours(query, k)
+	if err != nil {
+		return 0, err
+	}
+
+	return kDistance(neighbours), nil
+}
+
+// KDistances finds the k-distance of every item in the tree, as described for
+// KDistance.
+//
+// Rather than searching the tree separately for each item, the tree is
+// traversed against itself as for AllNearestNeighbours. The entire structure
+// of the tree is loaded into memory for the duration of the operation.
+//
+// found is called once for each item in the tree with its k-distance. Items are
+// reported in no particular order. If found returns an error, the search stops
+// and the error is returned.
+func (t *Tree[T]) KDistances(k int, found func(item T, kDistance float64) error) error {
+	loadChildren := t.NewTracer().loadChildren
+
+	roots, err := t.loadRootNodes(loadChildren)
+	if err != nil {
+		return err
+	}
+
+	return t.allNearestNeighbourNodes(roots, k, math.MaxFloat64, loadChildren, func(query *node[T], neighbours []nodeWithDistance[T]) error {
+		return found(query.item, kDistance(leafNeighbours(neighbours)))
+	})
+}
+
+// LocalOutlierFactor returns the Local Outlier Factor of the query with respect
+// to the items in the tree, using neighbourhoods of the k nearest items.
+//
+// The factor compares the density of items around the query with the density
+// around its neighbours. Values near one indicate that the query is about as
+// isolated as its neighbours, while values well above one indicate an outlier.
+// Where items have more than k neighbours at zero distance, their density is
+// infinite, and they are considered to be as dense as each other.
+//
+// Computing the factor requires searching for the neighbours of the query, its
+// neighbours, and their neighbours in turn.
+func (t *Tree[T]) LocalOutlierFactor(query T, k int) (float64, error) {
+	kDistanceOf := func(item T) (float64, error) {
+		return t.KDistance(item, k)
+	}
+
+	densityOf := func(item T) (float64, error) {
+		neighbours, err := t.outlierNeighbours(item, k)
+		if err != nil {
+			return 0, err
+		}
+
+		return localReachabilityDensity(neighbours, kDistanceOf)
+	}
+
+	neighbours, err := t.outlierNeighbours(query, k)
+	if err != nil {
+		return 0, err
+	}
+
+	density, err := localReachabilityDensity(neighbours, kDistanceOf)
+	if err != nil {
+		return 0, err
+	}
+
+	return localOutlierFactor(density, neighbours, densityOf)
+}
+
+// LocalOutlierFactors finds the Local Outlier Factor of every item in the tree,
+// as described for LocalOutlierFactor.
+//
+// Rather than retaining the neighbourhood of every item, the tree is traversed
+// against itself as for AllNearestNeighbours once to find the k-distance of
+// every item, again to find the density around every item, and a final time to
+// find the factor of every item. The entire structure of the tree is loaded
+// into memory for the duration of the operation, along with the k-distance and
+// density of every item, but the neighbourhoods are not retained.
+//
+// found is called once for each item in the tree with its factor. Items are
+// reported in no particular order. If found returns an error, the search stops
+// and the error is returned.
+func (t *Tree[T]) LocalOutlierFactors(k int, found func(item T, factor float64) error) error {
+	loadChildren := t.NewTracer().loadChildren
+
+	roots, err := t.loadRootNodes(loadChildren)
+	if err != nil {
+		return err
+	}
+
+	// The traversals share their roots, so each item is identified by the same
+	// leaf in every traversal
+	kDistances := make(map[*node[T]]float64)
+	err = t.allNearestNeighbourNodes(roots, k, math.MaxFloat64, loadChildren, func(query *node[T], neighbours []nodeWithDistance[T]) error {
+		kDistances[query] = kDistance(leafNeighbours(neighbours))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	kDistanceOf := func(n *node[T]) (float64, error) {
+		return kDistances[n], nil
+	}
+
+	densities := make(map[*node[T]]float64, len(kDistances))
+	err = t.allNearestNeighbourNodes(roots, k, math.MaxFloat64, loadChildren, func(query *node[T], neighbours []nodeWithDistance[T]) error {
+		densities[query], _ = localReachabilityDensity(leafNeighbours(neighbours), kDistanceOf)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	densityOf := func(n *node[T]) (float64, error) {
+		return densities[n], nil
+	}
+
+	return t.allNearestNeighbourNodes(roots, k, math.MaxFloat64, loadChildren, func(query *node[T], neighbours []nodeWithDistance[T]) error {
+		factor, _ := localOutlierFactor(densities[query], leafNeighbours(neighbours), densityOf)
+		return found(query.item, factor)
+	})
+}
+
+// outlierNeighbours returns the k nearest items to the query, excluding the
+// query itself.
+func (t *Tree[T]) outlierNeighbours(query T, k int) ([]ItemWithDistance[T], error) {
+	neighbours, err := t.findNearestWithTrace(query, k+1, math.MaxFloat64, 0, nil, t.NewTracer())
+	if err != nil {
+		return nil, err
+	}
+
+	// Neighbours are ordered by distance, so the query is the first of them if
+	// it is present
+	if len(neighbours) > 0 && neighbours[0].Distance == 0 {
+		return neighbours[1:], nil
+	}
+	if len(neighbours) > k {
+		neighbours = neighbours[:k]
+	}
+
+	return neighbours, nil
+}
+
+// leafNeighbours returns the neighbours found by allNearestNeighbourNodes with
+// their leaves as items, so that the leaves can identify them.
+func leafNeighbours[T any](neighbours []nodeWithDistance[T]) []ItemWithDistance[*node[T]] {
+	results := make([]ItemWithDistance[*node[T]], len(neighbours))
+	for i, n := range neighbours {
+		results[i] = ItemWithDistance[*node[T]]{n.node, n.distance}
+	}
+
+	return results
+}
+
+func kDistance[T any](neighbours []ItemWithDistance[T]) float64 {
+	if len(neighbours) == 0 {
+		return 0
+	}
+	return neighbours[len(neighbours)-1].Distance
+}
+
+func localOutlierFactor[T any](density float64, neighbours []ItemWithDistance[T], densityOf func(T) (float64, error)) (float64, error) {
+	if len(neighbours) == 0 {
+		return 1, nil
+	}
+
+	var sum float64
+	for _, n := range neighbours {
+		neighbourDensity, err := densityOf(n.Item)
+		if err != nil {
+			return 0, err
+		}
+
+		if math.IsInf(neighbourDensity, 1) && math.IsInf(density, 1) {
+			sum++
+		} else {
+			sum += neighbourDensity / density
+		}
+	}
+
+	return sum / float64(len(neighbours)), nil
+}
+
+func localReachabilityDensity[T any](neighbours []ItemWithDistance[T], kDistanceOf func(T) (float64, error)) (float64, error) {
+	var sum float64
+	for _, n := range neighbours {
+		d, err := kDistanceOf(n.Item)
+		if err != nil {
+			return 0, err
+		}
+
+		sum += math.Max(d, n.Distance)
+	}
+
+	if sum == 0 {
+		return math.Inf(1), nil
+	}
+
+	return float64(len(neighbours)) / sum, nil
+}
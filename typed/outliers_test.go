@@ -0,0 +1,258 @@
+package typed
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestOutliers(t *testing.T) {
+
+	linearNeighbours := func(query *Point, points []Point, k int) []ItemWithDistance[*Point] {
+		var results []ItemWithDistance[*Point]
+		for i := range points {
+			if &points[i] != query {
+				results = append(results, ItemWithDistance[*Point]{&points[i], distanceBetweenPoints(query, &points[i])})
+			}
+		}
+
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].Distance < results[j].Distance
+		})
+		if len(results) > k {
+			results = results[:k]
+		}
+
+		return results
+	}
+
+	linearKDistance := func(query *Point, points []Point, k int) float64 {
+		neighbours := linearNeighbours(query, points, k)
+		return neighbours[len(neighbours)-1].Distance
+	}
+
+	linearDensity := func(query *Point, points []Point, k int) float64 {
+		var sum float64
+		for _, n := range linearNeighbours(query, points, k) {
+			sum += math.Max(linearKDistance(n.Item, points, k), n.Distance)
+		}
+		return float64(k) / sum
+	}
+
+	linearFactor := func(query *Point, points []Point, k int) float64 {
+		var sum float64
+		for _, n := range linearNeighbours(query, points, k) {
+			sum += linearDensity(n.Item, points, k)
+		}
+		return sum / float64(k) / linearDensity(query, points, k)
+	}
+
+	const k = 5
+
+	tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+	points := randomPoints(200)
+	_, _ = insertPoints(points, tree)
+
+	t.Run("KDistance()", func(t *testing.T) {
+
+		t.Run("returns the distance to the kth nearest item", func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				query := randomPoint()
+
+				d, err := tree.KDistance(&query, k)
+				if err != nil {
+					t.Fatalf("Expected success but got error: %v", err)
+				}
+				if expected, actual := linearKDistance(&query, points, k), d; expected != actual {
+					t.Errorf("Expected k-distance %g but got %g", expected, actual)
+				}
+			}
+		})
+
+		t.Run("excludes the query from its own neighbours", func(t *testing.T) {
+			d, err := tree.KDistance(&points[0], 1)
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if expected, actual := linearKDistance(&points[0], points, 1), d; expected != actual {
+				t.Errorf("Expected k-distance %g but got %g", expected, actual)
+			}
+		})
+
+		t.Run("returns zero when there are no other items", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			p := randomPoint()
+			_ = tree.Insert(&p)
+
+			d, err := tree.KDistance(&p, k)
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if expected, actual := 0.0, d; expected != actual {
+				t.Errorf("Expected k-distance %g but got %g", expected, actual)
+			}
+		})
+	})
+
+	t.Run("KDistances()", func(t *testing.T) {
+
+		t.Run("reports the k-distance of every item", func(t *testing.T) {
+			seen := make(map[*Point]bool)
+
+			err := tree.KDistances(k, func(item *Point, kDistance float64) error {
+				seen[item] = true
+				if expected, actual := linearKDistance(item, points, k), kDistance; expected != actual {
+					t.Errorf("Expected k-distance %g for %v but got %g", expected, item, actual)
+				}
+				return nil
+			})
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if expected, actual := len(points), len(seen); expected != actual {
+				t.Errorf("Expected %d items to be reported but got %d", expected, actual)
+			}
+		})
+
+		t.Run("distinguishes duplicates of items which cannot be compared", func(t *testing.T) {
+			tree, _ := newSliceTree([]float64{100}, []float64{10}, []float64{0}, []float64{0})
+
+			kDistances := make(map[float64][]float64)
+			err := tree.KDistances(1, func(item []float64, kDistance float64) error {
+				kDistances[item[0]] = append(kDistances[item[0]], kDistance)
+				return nil
+			})
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			for item, expected := range map[float64][]float64{0: {0, 0}, 10: {10}, 100: {90}} {
+				if actual := kDistances[item]; len(actual) != len(expected) || actual[0] != expected[0] {
+					t.Errorf("Expected k-distances %v for %g but got %v", expected, item, actual)
+				}
+			}
+		})
+
+		t.Run("stops and returns errors from the callback", func(t *testing.T) {
+			stopErr := errors.New("stop")
+			calls := 0
+
+			err := tree.KDistances(k, func(item *Point, kDistance float64) error {
+				calls++
+				return stopErr
+			})
+
+			if expected, actual := stopErr, err; expected != actual {
+				t.Errorf("Expected error %v but got %v", expected, actual)
+			}
+			if expected, actual := 1, calls; expected != actual {
+				t.Errorf("Expected %d call but got %d", expected, actual)
+			}
+		})
+	})
+
+	t.Run("LocalOutlierFactor()", func(t *testing.T) {
+
+		t.Run("returns the factor for an arbitrary query", func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				query := randomPoint()
+
+				factor, err := tree.LocalOutlierFactor(&query, k)
+				if err != nil {
+					t.Fatalf("Expected success but got error: %v", err)
+				}
+				if expected, actual := linearFactor(&query, points, k), factor; math.Abs(expected-actual) > 1e-9 {
+					t.Errorf("Expected factor %g but got %g", expected, actual)
+				}
+			}
+		})
+
+		t.Run("scores isolated items above clustered ones", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			var points []Point
+			for i := 0; i < 20; i++ {
+				points = append(points, Point{float64(i % 5), float64(i / 5), 0})
+			}
+			points = append(points, Point{50, 50, 50})
+			_, _ = insertPoints(points, tree)
+
+			outlierFactor, _ := tree.LocalOutlierFactor(&points[len(points)-1], 3)
+			inlierFactor, _ := tree.LocalOutlierFactor(&points[7], 3)
+
+			if outlierFactor <= 2*inlierFactor {
+				t.Errorf("Expected outlier factor %g to be well above inlier factor %g", outlierFactor, inlierFactor)
+			}
+		})
+
+		t.Run("treats duplicate items as equally dense", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			points := make([]Point, 5)
+			_, _ = insertPoints(points, tree)
+
+			factor, err := tree.LocalOutlierFactor(&points[0], 2)
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if expected, actual := 1.0, factor; expected != actual {
+				t.Errorf("Expected factor %g but got %g", expected, actual)
+			}
+		})
+	})
+
+	t.Run("LocalOutlierFactors()", func(t *testing.T) {
+
+		t.Run("reports the factor of every item", func(t *testing.T) {
+			seen := make(map[*Point]bool)
+
+			err := tree.LocalOutlierFactors(k, func(item *Point, factor float64) error {
+				seen[item] = true
+				if expected, actual := linearFactor(item, points, k), factor; math.Abs(expected-actual) > 1e-9 {
+					t.Errorf("Expected factor %g for %v but got %g", expected, item, actual)
+				}
+				return nil
+			})
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if expected, actual := len(points), len(seen); expected != actual {
+				t.Errorf("Expected %d items to be reported but got %d", expected, actual)
+			}
+		})
+
+		t.Run("distinguishes duplicates of items which cannot be compared", func(t *testing.T) {
+			tree, _ := newSliceTree([]float64{100}, []float64{10}, []float64{0}, []float64{0})
+
+			factors := make(map[float64][]float64)
+			err := tree.LocalOutlierFactors(1, func(item []float64, factor float64) error {
+				factors[item[0]] = append(factors[item[0]], factor)
+				return nil
+			})
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if expected, actual := []float64{1, 1}, factors[0]; len(actual) != len(expected) || actual[0] != expected[0] || actual[1] != expected[1] {
+				t.Errorf("Expected factors %v for duplicates but got %v", expected, actual)
+			}
+		})
+
+		t.Run("returns errors from the store", func(t *testing.T) {
+			storeErr := errors.New("store failure")
+			tree, _ := NewTreeWithStore[*Point](&failingStore{err: storeErr}, 2, 1000.0, distanceBetweenPoints)
+
+			err := tree.LocalOutlierFactors(k, func(item *Point, factor float64) error {
+				return nil
+			})
+
+			if expected, actual := storeErr, err; expected != actual {
+				t.Errorf("Expected error %v but got %v", expected, actual)
+			}
+		})
+	})
+}
@@ -0,0 +1,133 @@
+package typed
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+)
+
+// PartitioningFunc represents a function which determines the partition for
+// the children of a parent item. A nil parent indicates the root of the tree.
+type PartitioningFunc[T any] func(parentItem *T) (partitionKey string)
+
+type partitionedStore[T any] struct {
+	partitionForParent PartitioningFunc[T]
+	stores             []Store[T]
+}
+
+func (s *partitionedStore[T]) AddItem(item T, parent *T, level int) error {
+	return s.AddItemContext(context.Background(), item, parent, level)
+}
+
+func (s *partitionedStore[T]) AddItemContext(ctx context.Context, item T, parent *T, level int) error {
+	store, err := s.storeForParent(parent)
+	if err != nil {
+		return err
+	}
+
+	return addItemContext(ctx, store, item, parent, level)
+}
+
+func (s *partitionedStore[T]) LoadChildren(parents ...*T) (children []LevelsWithItems[T], err error) {
+	return s.LoadChildrenContext(context.Background(), parents...)
+}
+
+func (s *partitionedStore[T]) LoadChildrenContext(ctx context.Context, parents ...*T) (children []LevelsWithItems[T], err error) {
+
+	entriesByStore := make(map[Store[T]]struct {
+		parents       []*T
+		parentIndices []int
+	})
+
+	for i, parent := range parents {
+		store, err := s.storeForParent(parent)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := entriesByStore[store]
+		entry.parents = append(entry.parents, parent)
+		entry.parentIndices = append(entry.parentIndices, i)
+		entriesByStore[store] = entry
+	}
+
+	children = make([]LevelsWithItems[T], len(parents))
+
+	var doneGroup sync.WaitGroup
+	doneGroup.Add(len(entriesByStore))
+
+	var nestedErr error
+	var nestedErrMutex sync.Mutex
+	for storeForEntry, entryForStore := range entriesByStore {
+		store := storeForEntry
+		entry := entryForStore
+
+		go func() {
+			defer doneGroup.Done()
+
+			c, err := loadChildrenContext(ctx, store, entry.parents...)
+			if err != nil {
+				nestedErrMutex.Lock()
+				nestedErr = err
+				nestedErrMutex.Unlock()
+				return
+			}
+
+			for i := range c {
+				children[entry.parentIndices[i]] = c[i]
+			}
+		}()
+	}
+
+	doneGroup.Wait()
+
+	return children, nestedErr
+}
+
+func (s *partitionedStore[T]) RemoveItem(item T, parent *T, level int) error {
+	return s.RemoveItemContext(context.Background(), item, parent, level)
+}
+
+func (s *partitionedStore[T]) RemoveItemContext(ctx context.Context, item T, parent *T, level int) error {
+	store, err := s.storeForParent(parent)
+	if err != nil {
+		return err
+	}
+
+	return removeItemContext(ctx, store, item, parent, level)
+}
+
+func (s *partitionedStore[T]) UpdateItem(item T, parent *T, level int) error {
+	store, err := s.storeForParent(parent)
+	if err != nil {
+		return err
+	}
+
+	return store.UpdateItem(item, parent, level)
+}
+
+func (s *partitionedStore[T]) storeForParent(parent *T) (Store[T], error) {
+	partitionKey := s.partitionForParent(parent)
+
+	hash := fnv.New32a()
+	_, err := hash.Write([]byte(partitionKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.stores[hash.Sum32()%uint32(len(s.stores))], nil
+}
+
+// NewPartitionedStore returns a store which distributes store operations across
+// the underlying stores using the specified partitioning function.
+//
+// The returned store implements ContextStore, passing contexts through to any
+// underlying stores which also implement ContextStore.
+//
+// Operations for a given partition key are always assigned to the same store.
+func NewPartitionedStore[T any](partitioningFunc PartitioningFunc[T], stores ...Store[T]) *partitionedStore[T] {
+	return &partitionedStore[T]{
+		partitionForParent: partitioningFunc,
+		stores:             stores,
+	}
+}
@@ -0,0 +1,255 @@
+package typed
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestPartitionedStore(t *testing.T) {
+
+	partitioningFunc := func(parentItem **Point) string {
+		if parentItem == nil {
+			return ""
+		}
+		return fmt.Sprintf("%v", *parentItem)
+	}
+
+	itemsFor := func(points []Point) (items []*Point) {
+		for i := range points {
+			items = append(items, &points[i])
+		}
+		return items
+	}
+
+	addPoints := func(items []*Point, s Store[*Point], t *testing.T) {
+		t.Helper()
+
+		for i := range items {
+			var parent **Point
+			if i > 0 {
+				parent = &items[i-1]
+			}
+
+			err := s.AddItem(items[i], parent, i)
+			if err != nil {
+				t.Fatalf("Expected item to be added but got error: %v", err)
+			}
+		}
+	}
+
+	countItems := func(items map[interface{}]map[int][]*Point) int {
+		total := 0
+
+		for _, levels := range items {
+			for _, children := range levels {
+				total += len(children)
+			}
+		}
+
+		return total
+	}
+
+	t.Run("consistently uses the same stores for items", func(t *testing.T) {
+		s1 := NewInMemoryStore(distanceBetweenPoints)
+		s2 := NewInMemoryStore(distanceBetweenPoints)
+		s := NewPartitionedStore[*Point](partitioningFunc, s1, s2)
+
+		items := itemsFor(randomPoints(100))
+
+		stores := make([]Store[*Point], len(items))
+		for i := range items {
+			stores[i], _ = s.storeForParent(&items[i])
+
+			if stores[i] != s1 && stores[i] != s2 {
+				t.Fatalf("Expected returned store to be one of the provided stores but was %v", stores[i])
+			}
+		}
+
+		for i := range items {
+			store, _ := s.storeForParent(&items[i])
+			if store != stores[i] {
+				t.Fatalf("Expected point %d to use store %v but was %v", i, stores[i], store)
+			}
+		}
+	})
+
+	t.Run("distributes AddItem operations across underlying stores", func(t *testing.T) {
+		s1 := NewInMemoryStore(distanceBetweenPoints)
+		s2 := NewInMemoryStore(distanceBetweenPoints)
+		s3 := NewInMemoryStore(distanceBetweenPoints)
+		s4 := NewInMemoryStore(distanceBetweenPoints)
+		s := NewPartitionedStore[*Point](partitioningFunc, s1, s2, s3, s4)
+
+		items := itemsFor(randomPoints(100))
+		addPoints(items, s, t)
+
+		s1ItemCount := len(s1.items)
+		s2ItemCount := len(s2.items)
+		s3ItemCount := len(s3.items)
+		s4ItemCount := len(s4.items)
+
+		if s1ItemCount == 0 {
+			t.Errorf("Expected some points in the first store but found none")
+		}
+		if s2ItemCount == 0 {
+			t.Errorf("Expected some points in the second store but found none")
+		}
+		if s3ItemCount == 0 {
+			t.Errorf("Expected some points in the third store but found none")
+		}
+		if s4ItemCount == 0 {
+			t.Errorf("Expected some points in the fourth store but found none")
+		}
+		if expected, actual := len(items), s1ItemCount+s2ItemCount+s3ItemCount+s4ItemCount; expected != actual {
+			t.Errorf("Expected points in all stores to total %d but was %d", expected, actual)
+		}
+	})
+
+	t.Run("distributes LoadChildren operations across underlying stores", func(t *testing.T) {
+		s1 := NewInMemoryStore(distanceBetweenPoints)
+		s2 := NewInMemoryStore(distanceBetweenPoints)
+		s3 := NewInMemoryStore(distanceBetweenPoints)
+		s4 := NewInMemoryStore(distanceBetweenPoints)
+		s := NewPartitionedStore[*Point](partitioningFunc, s1, s2, s3, s4)
+
+		items := itemsFor(randomPoints(100))
+		addPoints(items, s, t)
+
+		parents := make([]**Point, len(items))
+		for i := range items[:len(items)-1] {
+			parents[i+1] = &items[i]
+		}
+
+		children, err := s.LoadChildren(parents...)
+		if err != nil {
+			t.Fatalf("Expected children to be loaded but got error: %v", err)
+		}
+
+		if expected, actual := len(items), len(children); expected != actual {
+			t.Errorf("Expected children to be returned in %d levels but got %d", expected, actual)
+		}
+	})
+
+	t.Run("distributes RemoveItem operations across underlying stores", func(t *testing.T) {
+		s1 := NewInMemoryStore(distanceBetweenPoints)
+		s2 := NewInMemoryStore(distanceBetweenPoints)
+		s3 := NewInMemoryStore(distanceBetweenPoints)
+		s4 := NewInMemoryStore(distanceBetweenPoints)
+		s := NewPartitionedStore[*Point](partitioningFunc, s1, s2, s3, s4)
+
+		items := itemsFor(randomPoints(100))
+		addPoints(items, s, t)
+
+		for i := range items {
+			var parent **Point
+			if i > 0 {
+				parent = &items[i-1]
+			}
+
+			err := s.RemoveItem(items[i], parent, i)
+			if err != nil {
+				t.Fatalf("Expected point to be removed but got error: %v", err)
+			}
+		}
+
+		if pointCount := countItems(s1.items); pointCount > 0 {
+			t.Errorf("Expected first store to be empty but found %d points", pointCount)
+		}
+		if pointCount := countItems(s2.items); pointCount > 0 {
+			t.Errorf("Expected second store to be empty but found %d points", pointCount)
+		}
+		if pointCount := countItems(s3.items); pointCount > 0 {
+			t.Errorf("Expected third store to be empty but found %d points", pointCount)
+		}
+		if pointCount := countItems(s4.items); pointCount > 0 {
+			t.Errorf("Expected fourth store to be empty but found %d points", pointCount)
+		}
+	})
+
+	t.Run("distributes UpdateItem operations across underlying stores", func(t *testing.T) {
+		s1 := NewInMemoryStore(distanceBetweenPoints)
+		s2 := NewInMemoryStore(distanceBetweenPoints)
+		s3 := NewInMemoryStore(distanceBetweenPoints)
+		s4 := NewInMemoryStore(distanceBetweenPoints)
+		s := NewPartitionedStore[*Point](partitioningFunc, s1, s2, s3, s4)
+
+		items := itemsFor(randomPoints(100))
+		addPoints(items, s, t)
+
+		for i := range items {
+			err := s.UpdateItem(items[i], &items[i], 0)
+			if err != nil {
+				t.Fatalf("Expected point to be updated but got error: %v", err)
+			}
+		}
+
+		for i := range items {
+			children, err := s.LoadChildren(&items[i])
+			if err != nil {
+				t.Fatalf("Expected children to be loaded but got error: %v", err)
+			}
+
+			if expected, actual := 1, len(children); expected != actual {
+				t.Fatalf("Expected one set of children for point %d but found %d", i, actual)
+			}
+			if actual := len(children[0].items); actual == 0 {
+				t.Fatalf("Expected at least one level of children for point %d but found %d", i, actual)
+			}
+			if expected, actual := 1, len(children[0].items[0]); expected != actual {
+				t.Fatalf("Expected one child item at level 0 for point %d but found %d", i, actual)
+			}
+		}
+	})
+
+	t.Run("passes contexts through to context-aware underlying stores", func(t *testing.T) {
+		s1 := newContextRecordingStore(distanceBetweenPoints)
+		s2 := newContextRecordingStore(distanceBetweenPoints)
+		s := NewPartitionedStore[*Point](partitioningFunc, s1, s2)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		items := itemsFor(randomPoints(100))
+		for i := range items {
+			var parent **Point
+			if i > 0 {
+				parent = &items[i-1]
+			}
+
+			err := s.AddItemContext(ctx, items[i], parent, i)
+			if err != nil {
+				t.Fatalf("Expected item to be added but got error: %v", err)
+			}
+		}
+
+		parents := make([]**Point, len(items))
+		for i := range items {
+			parents[i] = &items[i]
+		}
+		_, err := s.LoadChildrenContext(ctx, parents...)
+		if err != nil {
+			t.Fatalf("Expected children to be loaded but got error: %v", err)
+		}
+
+		err = s.RemoveItemContext(ctx, items[1], &items[0], 1)
+		if err != nil {
+			t.Fatalf("Expected item to be removed but got error: %v", err)
+		}
+
+		s1.expectOnlyContext(t, ctx)
+		s2.expectOnlyContext(t, ctx)
+	})
+
+	t.Run("returns the context error when cancelled", func(t *testing.T) {
+		s := NewPartitionedStore[*Point](partitioningFunc, NewInMemoryStore(distanceBetweenPoints), NewInMemoryStore(distanceBetweenPoints))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := s.LoadChildrenContext(ctx, nil)
+		if expected, actual := context.Canceled, err; expected != actual {
+			t.Errorf("Expected error %v but got %v", expected, actual)
+		}
+	})
+}
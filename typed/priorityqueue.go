@@ -0,0 +1,62 @@
+package typed
+
+import "container/heap"
+
+// queueItem is implemented by the items of a priorityQueue, so that leaves can
+// be ordered before other items with the same key.
+type queueItem interface {
+	isLeaf() bool
+}
+
+type priorityQueueEntry[T queueItem] struct {
+	item T
+	key  float64
+	seq  int
+}
+
+// priorityQueue is a priority queue of items, ordered from lowest to highest
+// key. Leaves are ordered before other items with the same key, and otherwise
+// items with the same key are ordered by when they were pushed.
+type priorityQueue[T queueItem] struct {
+	entries []priorityQueueEntry[T]
+	seq     int
+}
+
+func (q *priorityQueue[T]) Len() int {
+	return len(q.entries)
+}
+
+func (q *priorityQueue[T]) Less(i, j int) bool {
+	a, b := &q.entries[i], &q.entries[j]
+	if a.key != b.key {
+		return a.key < b.key
+	}
+	if aLeaf, bLeaf := a.item.isLeaf(), b.item.isLeaf(); aLeaf != bLeaf {
+		return aLeaf
+	}
+	return a.seq < b.seq
+}
+
+func (q *priorityQueue[T]) Pop() interface{} {
+	last := q.entries[len(q.entries)-1]
+	q.entries = q.entries[:len(q.entries)-1]
+	return last
+}
+
+func (q *priorityQueue[T]) Push(x interface{}) {
+	q.entries = append(q.entries, x.(priorityQueueEntry[T]))
+}
+
+func (q *priorityQueue[T]) Swap(i, j int) {
+	q.entries[i], q.entries[j] = q.entries[j], q.entries[i]
+}
+
+func (q *priorityQueue[T]) pop() (item T, key float64) {
+	entry := heap.Pop(q).(priorityQueueEntry[T])
+	return entry.item, entry.key
+}
+
+func (q *priorityQueue[T]) push(item T, key float64) {
+	heap.Push(q, priorityQueueEntry[T]{item: item, key: key, seq: q.seq})
+	q.seq++
+}
@@ -0,0 +1,168 @@
+package typed
+
+import "math/rand"
+
+// Sample returns up to the specified number of items drawn uniformly at random
+// from the tree, without replacement, using the specified source of
+// randomness. If the tree has fewer items, every item is returned.
+//
+// If the tree’s Store implements SubtreeSizeStore, random positions within the
+// tree are chosen from the total number of items, and only the paths leading
+// to those positions are loaded. Otherwise, the entire tree is traversed and
+// sampled as it is loaded, without holding more than the sample in memory.
+//
+// Items are returned in no particular order.
+//
+// Multiple calls to Sample and Insert are safe to make concurrently. However,
+// the subtree sizes used to choose positions may then change while the tree is
+// being descended, so items inserted concurrently are not sampled uniformly,
+// and fewer than the specified number of items may be returned even when the
+// tree has enough.
+func (t *Tree[T]) Sample(n int, rng *rand.Rand) (items []T, err error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	tracer := t.NewTracer()
+
+	if _, ok := t.store.(SubtreeSizeStore[T]); ok {
+		return t.sampleBySize(n, rng, tracer)
+	}
+
+	return t.sampleByReservoir(n, rng, tracer)
+}
+
+// sampleByReservoir samples items by traversing the entire tree, keeping each
+// item with a probability which ensures that every item is equally likely to
+// be kept.
+func (t *Tree[T]) sampleByReservoir(n int, rng *rand.Rand, tracer *Tracer[T]) (items []T, err error) {
+	seen := 0
+
+	err = traverseBreadthFirst(t, tracer, true, func(item T, _ struct{}, _ int) (struct{}, error) {
+		if len(items) < n {
+			items = append(items, item)
+		} else if i := rng.Intn(seen + 1); i < n {
+			items[i] = item
+		}
+		seen++
+		return struct{}{}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// sampleBySize samples items by choosing distinct positions amongst all the
+// items in the tree, and descending towards each position using the sizes of
+// the subtrees along the way.
+func (t *Tree[T]) sampleBySize(n int, rng *rand.Rand, tracer *Tracer[T]) (items []T, err error) {
+	roots, err := t.loadRootNodes(tracer.loadChildren)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes, err := t.subtreeSizes(roots, tracer)
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, size := range sizes {
+		total += size
+	}
+	if n > total {
+		n = total
+	}
+
+	// Choose distinct positions using Floyd’s algorithm
+	chosen := make(map[int]bool, n)
+	positions := make([]int, 0, n)
+	for j := total - n; j < total; j++ {
+		position := rng.Intn(j + 1)
+		if chosen[position] {
+			position = j
+		}
+		chosen[position] = true
+		positions = append(positions, position)
+	}
+
+	// A target is a position within the subtree of a node, where the node’s
+	// own item is at position zero and its descendants follow
+	type target struct {
+		node     *node[T]
+		position int
+	}
+
+	var targets []target
+	for _, position := range positions {
+		for i, size := range sizes {
+			if position < size {
+				targets = append(targets, target{roots[i], position})
+				break
+			}
+			position -= size
+		}
+	}
+
+	for len(targets) > 0 {
+		var toExpand []*node[T]
+		for _, tg := range targets {
+			if tg.position == 0 {
+				items = append(items, tg.node.item)
+			} else {
+				toExpand = append(toExpand, tg.node)
+			}
+		}
+
+		err = t.expandNodes(toExpand, tracer.loadChildren)
+		if err != nil {
+			return nil, err
+		}
+
+		// Load the sizes of the children of every expanded node together
+		var parents, children []*node[T]
+		seen := make(map[*node[T]]bool)
+		for _, parent := range toExpand {
+			if !seen[parent] {
+				seen[parent] = true
+				parents = append(parents, parent)
+				children = append(children, parent.expanded[1:]...)
+			}
+		}
+
+		var sizes []int
+		if len(children) > 0 {
+			sizes, err = t.subtreeSizes(children, tracer)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		childSizes := make(map[*node[T]][]int, len(parents))
+		for _, parent := range parents {
+			childCount := len(parent.expanded) - 1
+			childSizes[parent], sizes = sizes[:childCount], sizes[childCount:]
+		}
+
+		var next []target
+		for _, tg := range targets {
+			if tg.position == 0 {
+				continue
+			}
+
+			position := tg.position - 1
+			for i, size := range childSizes[tg.node] {
+				if position < size {
+					next = append(next, target{tg.node.expanded[i+1], position})
+					break
+				}
+				position -= size
+			}
+		}
+		targets = next
+	}
+
+	return items, nil
+}
@@ -0,0 +1,149 @@
+package typed
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+type loadCountingStore struct {
+	*inMemoryStore[*Point]
+	loadedCount int
+}
+
+func (s *loadCountingStore) LoadChildren(parents ...**Point) ([]LevelsWithItems[*Point], error) {
+	s.loadedCount += len(parents)
+	return s.inMemoryStore.LoadChildren(parents...)
+}
+
+func TestSample(t *testing.T) {
+
+	treesFor := func(points []Point) map[string]*Tree[*Point] {
+		store := NewInMemoryStore(distanceBetweenPoints)
+		tree, _ := NewTreeWithStore[*Point](store, 2, 1000.0, distanceBetweenPoints)
+		_, _ = insertPoints(points, tree)
+
+		plainTree, _ := NewTreeWithStore[*Point](struct{ Store[*Point] }{store}, 2, 1000.0, distanceBetweenPoints)
+
+		return map[string]*Tree[*Point]{
+			"with subtree sizes":    tree,
+			"without subtree sizes": plainTree,
+		}
+	}
+
+	t.Run("returns nothing for an empty tree", func(t *testing.T) {
+		for name, tree := range treesFor(nil) {
+			items, err := tree.Sample(5, rand.New(rand.NewSource(1)))
+
+			if err != nil {
+				t.Fatalf("Expected success %s but got error: %v", name, err)
+			}
+			if expected, actual := 0, len(items); expected != actual {
+				t.Errorf("Expected %d items %s but got %d", expected, name, actual)
+			}
+		}
+	})
+
+	t.Run("returns the requested number of distinct items from the tree", func(t *testing.T) {
+		points := randomPoints(200)
+
+		for name, tree := range treesFor(points) {
+			items, err := tree.Sample(50, rand.New(rand.NewSource(1)))
+			if err != nil {
+				t.Fatalf("Expected success %s but got error: %v", name, err)
+			}
+
+			if expected, actual := 50, len(items); expected != actual {
+				t.Fatalf("Expected %d items %s but got %d", expected, name, actual)
+			}
+
+			inTree := make(map[*Point]bool)
+			for i := range points {
+				inTree[&points[i]] = true
+			}
+
+			seen := make(map[*Point]bool)
+			for _, item := range items {
+				if !inTree[item] {
+					t.Errorf("Expected %v %s to be from the tree", item, name)
+				}
+				if seen[item] {
+					t.Errorf("Expected %v %s to be sampled only once", item, name)
+				}
+				seen[item] = true
+			}
+		}
+	})
+
+	t.Run("returns every item when more are requested than the tree holds", func(t *testing.T) {
+		points := randomPoints(20)
+
+		for name, tree := range treesFor(points) {
+			items, err := tree.Sample(100, rand.New(rand.NewSource(1)))
+
+			if err != nil {
+				t.Fatalf("Expected success %s but got error: %v", name, err)
+			}
+			if expected, actual := len(points), len(items); expected != actual {
+				t.Errorf("Expected %d items %s but got %d", expected, name, actual)
+			}
+		}
+	})
+
+	t.Run("samples every item with equal probability", func(t *testing.T) {
+		const draws = 10000
+
+		points := randomPoints(10)
+
+		for name, tree := range treesFor(points) {
+			rng := rand.New(rand.NewSource(1))
+			counts := make(map[*Point]int)
+
+			for i := 0; i < draws; i++ {
+				items, err := tree.Sample(2, rng)
+				if err != nil {
+					t.Fatalf("Expected success %s but got error: %v", name, err)
+				}
+				for _, item := range items {
+					counts[item]++
+				}
+			}
+
+			expected := 2 * draws / len(points)
+			for i := range points {
+				if actual := counts[&points[i]]; actual < expected*8/10 || actual > expected*12/10 {
+					t.Errorf("Expected %v %s to be sampled about %d times but got %d", &points[i], name, expected, actual)
+				}
+			}
+		}
+	})
+
+	t.Run("loads only part of the tree when the store reports subtree sizes", func(t *testing.T) {
+		store := &loadCountingStore{inMemoryStore: NewInMemoryStore(distanceBetweenPoints)}
+		tree, _ := NewTreeWithStore[*Point](store, 2, 1000.0, distanceBetweenPoints)
+
+		points := randomPoints(1000)
+		_, _ = insertPoints(points, tree)
+
+		store.loadedCount = 0
+		_, err := tree.Sample(5, rand.New(rand.NewSource(1)))
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if store.loadedCount >= len(points)/2 {
+			t.Errorf("Expected fewer than %d items to be loaded but got %d", len(points)/2, store.loadedCount)
+		}
+	})
+
+	t.Run("returns errors from the store", func(t *testing.T) {
+		storeErr := errors.New("store failure")
+		tree, _ := NewTreeWithStore[*Point](&failingStore{err: storeErr}, 2, 1000.0, distanceBetweenPoints)
+
+		_, err := tree.Sample(5, rand.New(rand.NewSource(1)))
+
+		if expected, actual := storeErr, err; expected != actual {
+			t.Errorf("Expected error %v but got %v", expected, actual)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package typed
+
+import "context"
+
+// Store implementations allow entire Trees to be made accessible in an
+// extensible way. Implementations may provide capabilities such as persistence
+// and serialisation to various formats and data stores.
+//
+// Each Store instance is responsible for storing and retrieval of the data for
+// a single Tree. This implies that any keys or identifiers required for the
+// loading and saving of a tree should be known to the instance of the store.
+//
+// Store implementations will typically need to know about the type of items
+// being stored in the tree.
+//
+// Stores should implement distance-identity semantics; two items whose distance
+// is exactly zero should be considered the same item.
+//
+// Parents are specified by pointer only so that nil can indicate the root of
+// the tree. The pointers are not retained by the tree and may differ between
+// calls for the same parent, so stores should identify parents by the items
+// they point to rather than by the pointers themselves.
+type Store[T any] interface {
+
+	// AddItem saves an item to the store as a child of the specified parent
+	// item, at the given level. The parent may be nil, indicating that an item
+	// is being added at the root of the tree.
+	//
+	// Implementations are free to assume that this will only be called for new,
+	// never-before-seen items.
+	AddItem(item T, parent *T, level int) error
+
+	// LoadChildren returns the explicit child items of the specified parents
+	// item along with their levels. If a parent is nil, this is expected to
+	// return the root items (which are children of the nil parent). The
+	// children are expected to be returned in the same order as their parents.
+	LoadChildren(parents ...*T) (children []LevelsWithItems[T], err error)
+
+	// RemoveItem disassociates an item in the store from the specified parent
+	// at the given level. If no such item exists, this operation should have no
+	// effect.
+	//
+	// Implementations are free to completely delete the item itself along with
+	// any relationships to child items, but should bear in mind that children
+	// should continue to exist as orphans and will be re-parented to other
+	// items (via calls to UpdateItem).
+	RemoveItem(item T, parent *T, level int) error
+
+	// UpdateItem updates the parent and level of a given item. It is valid for
+	// child items to be re-parented; the item, which must already exist in the
+	// store, is associated with the new parent and level.
+	//
+	// Implementations are free to assume that this will only be called for
+	// items which have previously been added via AddItem.
+	UpdateItem(item T, parent *T, level int) error
+}
+
+// ContextStore may optionally be implemented by Stores which can abandon their
+// operations when a context is cancelled or its deadline expires, such as
+// those backed by remote databases.
+//
+// Context-aware Tree operations (such as FindNearestContext) use these methods
+// in preference to their Store equivalents when they are available. UpdateItem
+// has no context-aware equivalent, as it is only used to re-parent the orphaned
+// children of a removed item, which must be completed once removal has begun.
+type ContextStore[T any] interface {
+	Store[T]
+
+	// AddItemContext is the context-aware equivalent of AddItem.
+	AddItemContext(ctx context.Context, item T, parent *T, level int) error
+
+	// LoadChildrenContext is the context-aware equivalent of LoadChildren.
+	LoadChildrenContext(ctx context.Context, parents ...*T) (children []LevelsWithItems[T], err error)
+
+	// RemoveItemContext is the context-aware equivalent of RemoveItem.
+	RemoveItemContext(ctx context.Context, item T, parent *T, level int) error
+}
+
+// SubtreeSizeStore may optionally be implemented by Stores which can report the
+// number of items beneath items in the tree without loading them all, such as
+// those which maintain counts as items are added and removed.
+//
+// Operations which only need to know how many items a subtree contains (such
+// as CountWithin) use this in preference to loading the subtree.
+type SubtreeSizeStore[T any] interface {
+	Store[T]
+
+	// LoadSubtreeSizes returns the number of items in the subtree of each of
+	// the specified items, including the item itself. The sizes are expected to
+	// be returned in the same order as the items.
+	LoadSubtreeSizes(items ...T) (sizes []int, err error)
+}
+
+func addItemContext[T any](ctx context.Context, store Store[T], item T, parent *T, level int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if cs, ok := store.(ContextStore[T]); ok {
+		return cs.AddItemContext(ctx, item, parent, level)
+	}
+	return store.AddItem(item, parent, level)
+}
+
+func loadChildrenContext[T any](ctx context.Context, store Store[T], parents ...*T) ([]LevelsWithItems[T], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if cs, ok := store.(ContextStore[T]); ok {
+		return cs.LoadChildrenContext(ctx, parents...)
+	}
+	return store.LoadChildren(parents...)
+}
+
+func removeItemContext[T any](ctx context.Context, store Store[T], item T, parent *T, level int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if cs, ok := store.(ContextStore[T]); ok {
+		return cs.RemoveItemContext(ctx, item, parent, level)
+	}
+	return store.RemoveItem(item, parent, level)
+}
+
+// parentsFor returns pointers to each of the specified items, for specifying
+// them as parents to a Store.
+func parentsFor[T any](items []T) []*T {
+	parents := make([]*T, len(items))
+	for i := range items {
+		parents[i] = &items[i]
+	}
+
+	return parents
+}
@@ -0,0 +1,333 @@
+package typed
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Tracer represents a record for performance metrics of Tree operations.
+//
+// Tracers for a given tree can be created using the tree’s NewTracer method.
+//
+// Tracers are not thread safe and should not be shared by multiple Goroutines.
+type Tracer[T any] struct {
+	tree                  *Tree[T]
+	ctx                   context.Context
+	TotalCoveredSetSize   int
+	MaxCoverSetSize       int
+	MaxLevelsTraversed    int
+	LoadChildrenCount     int
+	TotalLoadChildrenTime time.Duration
+	TotalTime             time.Duration
+}
+
+// CountWithin returns the number of items in the tree which are within the
+// specified distance of the query item.
+func (t *Tracer[T]) CountWithin(query T, radius float64) (count int, err error) {
+	t.doWithTrace(func() {
+		count, err = t.tree.countWithinWithTrace(query, radius, t)
+	})
+	return
+}
+
+// FindFurthest returns the furthest items in the tree from the specified query
+// item, up to the specified maximum number of results, which are at least the
+// specified minimum distance away.
+//
+// Results are returned with their distances from the query item, in order from
+// furthest to closest.
+//
+// If no items are found matching the given criteria, an empty result set is
+// returned.
+func (t *Tracer[T]) FindFurthest(query T, maxResults int, minDistance float64) (results []ItemWithDistance[T], err error) {
+	t.doWithTrace(func() {
+		results, err = t.tree.findFurthestWithTrace(query, maxResults, minDistance, t)
+	})
+	return
+}
+
+// FindNearest returns the nearest items in the tree to the specified query
+// item, up to the specified maximum number of results and maximum distance.
+//
+// Results are returned with their distances from the query item, in order from
+// closest to furthest.
+//
+// If no items are found matching the given criteria, an empty result set is
+// returned.
+func (t *Tracer[T]) FindNearest(query T, maxResults int, maxDistance float64) (results []ItemWithDistance[T], err error) {
+	t.doWithTrace(func() {
+		results, err = t.tree.findNearestWithTrace(query, maxResults, maxDistance, 0, nil, t)
+	})
+	return
+}
+
+// FindNearestApprox returns approximate nearest items in the tree to the
+// specified query item, up to the specified maximum number of results and
+// maximum distance.
+//
+// epsilon is the non-negative relative error permitted in the results. The
+// distance of the nth result is within a factor of (1 + epsilon) of the
+// distance of the true nth nearest item.
+//
+// An error is returned if epsilon is negative.
+//
+// Results are returned with their distances from the query item, in order from
+// closest to furthest.
+//
+// If no items are found matching the given criteria, an empty result set is
+// returned.
+func (t *Tracer[T]) FindNearestApprox(query T, maxResults int, maxDistance float64, epsilon float64) (results []ItemWithDistance[T], err error) {
+	t.doWithTrace(func() {
+		results, err = t.tree.findNearestWithTrace(query, maxResults, maxDistance, epsilon, nil, t)
+	})
+	return
+}
+
+// FindNearestBatch returns the nearest items in the tree to each of the
+// specified query items, up to the specified maximum number of results and
+// maximum distance per query.
+//
+// The queries are traversed through the tree together, so that the children
+// needed by all queries at each level are loaded with a single call.
+//
+// Results are returned in the same order as the queries. Each set of results
+// contains items with their distances from the corresponding query item, in
+// order from closest to furthest.
+func (t *Tracer[T]) FindNearestBatch(queries []T, maxResults int, maxDistance float64) (results [][]ItemWithDistance[T], err error) {
+	t.doWithTrace(func() {
+		results, err = t.tree.findNearestBatchWithTrace(queries, maxResults, maxDistance, t)
+	})
+	return
+}
+
+// FindNearestContext returns the nearest items in the tree to the specified
+// query item, up to the specified maximum number of results and maximum
+// distance.
+//
+// If the context is cancelled or its deadline expires before the search is
+// complete, the search is abandoned and the context’s error is returned.
+//
+// Results are returned with their distances from the query item, in order from
+// closest to furthest.
+//
+// If no items are found matching the given criteria, an empty result set is
+// returned.
+func (t *Tracer[T]) FindNearestContext(ctx context.Context, query T, maxResults int, maxDistance float64) (results []ItemWithDistance[T], err error) {
+	t.doWithTraceContext(ctx, func() {
+		results, err = t.tree.findNearestWithTrace(query, maxResults, maxDistance, 0, nil, t)
+	})
+	return
+}
+
+// FindNearestMatching returns the nearest items in the tree to the specified
+// query item which are accepted by the matches function, up to the specified
+// maximum number of results and maximum distance.
+//
+// Items which are not accepted do not count towards the maximum number of
+// results, but are still traversed so that matching items beneath them can be
+// found.
+//
+// Results are returned with their distances from the query item, in order from
+// closest to furthest.
+//
+// If no items are found matching the given criteria, an empty result set is
+// returned.
+func (t *Tracer[T]) FindNearestMatching(query T, maxResults int, maxDistance float64, matches MatchFunc[T]) (results []ItemWithDistance[T], err error) {
+	t.doWithTrace(func() {
+		results, err = t.tree.findNearestWithTrace(query, maxResults, maxDistance, 0, matches, t)
+	})
+	return
+}
+
+// FindReverseNearest returns the items in the tree which would have the
+// specified query item amongst their nearest neighbours, if the query item were
+// to be inserted into the tree.
+//
+// An item is included if fewer than maxNeighbours other items in the tree are
+// closer to it than the query item is.
+//
+// Results are returned with their distances from the query item, in order from
+// closest to furthest.
+//
+// If no items are found matching the given criteria, an empty result set is
+// returned.
+func (t *Tracer[T]) FindReverseNearest(query T, maxNeighbours int) (results []ItemWithDistance[T], err error) {
+	t.doWithTrace(func() {
+		results, err = t.tree.findReverseNearestWithTrace(query, maxNeighbours, t)
+	})
+	return
+}
+
+// FindWithin returns all items in the tree which are within the specified
+// distance of the query item.
+//
+// Results are returned with their distances from the query item, in order from
+// closest to furthest.
+//
+// If no items are found within the given distance, an empty result set is
+// returned.
+func (t *Tracer[T]) FindWithin(query T, radius float64) (results []ItemWithDistance[T], err error) {
+	t.doWithTrace(func() {
+		results, err = t.tree.findWithinWithTrace(query, radius, t)
+	})
+	return
+}
+
+// Insert inserts the specified item into the tree.
+func (t *Tracer[T]) Insert(item T) (err error) {
+	t.doWithTrace(func() {
+		err = t.tree.insertWithTrace(item, t)
+	})
+	return
+}
+
+// InsertContext inserts the specified item into the tree.
+//
+// If the context is cancelled or its deadline expires before the item is
+// inserted, the insertion is abandoned and the context’s error is returned.
+func (t *Tracer[T]) InsertContext(ctx context.Context, item T) (err error) {
+	t.doWithTraceContext(ctx, func() {
+		err = t.tree.insertWithTrace(item, t)
+	})
+	return
+}
+
+// KernelDensity returns an estimate of the density of items in the tree around
+// the query item, as the sum of the kernel’s weighting of each item at the
+// specified bandwidth, to within the specified tolerance.
+func (t *Tracer[T]) KernelDensity(query T, kernel Kernel, bandwidth float64, tolerance float64) (density float64, err error) {
+	t.doWithTrace(func() {
+		density, err = t.tree.kernelDensityWithTrace(query, kernel, bandwidth, tolerance, t)
+	})
+	return
+}
+
+// Remove removes the given item from the tree. If no such item exists in the
+// tree, this has no effect.
+//
+// removed will be the item that was successfully removed, and ok reports
+// whether a matching item was found.
+func (t *Tracer[T]) Remove(item T) (removed T, ok bool, err error) {
+	t.doWithTrace(func() {
+		removed, ok, err = t.tree.removeWithTrace(item, t)
+	})
+	return
+}
+
+// RemoveContext removes the given item from the tree. If no such item exists in
+// the tree, this has no effect.
+//
+// If the context is cancelled or its deadline expires before the item is
+// found, the removal is abandoned and the context’s error is returned. Once the
+// item has been removed, the re-parenting of its children is always completed.
+//
+// removed will be the item that was successfully removed, and ok reports
+// whether a matching item was found.
+func (t *Tracer[T]) RemoveContext(ctx context.Context, item T) (removed T, ok bool, err error) {
+	t.doWithTraceContext(ctx, func() {
+		removed, ok, err = t.tree.removeWithTrace(item, t)
+	})
+	return
+}
+
+func (t *Tracer[T]) String() string {
+	if t == nil {
+		return "nil"
+	}
+
+	return fmt.Sprintf("%v, total covered set size: %d, max visible cover set size: %d, levels traversed: %d, load children count: %d, total load children time: %v", t.TotalTime, t.TotalCoveredSetSize, t.MaxCoverSetSize, t.MaxLevelsTraversed, t.LoadChildrenCount, t.TotalLoadChildrenTime)
+}
+
+func (t *Tracer[T]) addItem(item T, parent *T, level int) error {
+	if t.ctx == nil {
+		return t.tree.store.AddItem(item, parent, level)
+	}
+	return addItemContext(t.ctx, t.tree.store, item, parent, level)
+}
+
+func (t *Tracer[T]) contextErr() error {
+	if t.ctx == nil {
+		return nil
+	}
+	return t.ctx.Err()
+}
+
+func (t *Tracer[T]) doWithTrace(f func()) {
+	var startTime time.Time
+
+	t.reset()
+
+	defer func() {
+		t.TotalTime = time.Now().Sub(startTime)
+	}()
+
+	startTime = time.Now()
+	f()
+}
+
+func (t *Tracer[T]) doWithTraceContext(ctx context.Context, f func()) {
+	t.ctx = ctx
+	defer func() {
+		t.ctx = nil
+	}()
+
+	t.doWithTrace(f)
+}
+
+func (t *Tracer[T]) loadChildren(parents ...*T) ([]LevelsWithItems[T], error) {
+	var startTime time.Time
+
+	t.LoadChildrenCount++
+
+	defer func() {
+		t.TotalLoadChildrenTime += time.Now().Sub(startTime)
+	}()
+
+	startTime = time.Now()
+
+	if t.ctx == nil {
+		return t.tree.store.LoadChildren(parents...)
+	}
+	return loadChildrenContext(t.ctx, t.tree.store, parents...)
+}
+
+func (t *Tracer[T]) recordLevel(cs coverSet[T]) {
+	t.TotalCoveredSetSize = cs.totalItemCount
+
+	size := cs.visibleItemCount
+	if size > t.MaxCoverSetSize {
+		t.MaxCoverSetSize = size
+	}
+
+	t.MaxLevelsTraversed++
+}
+
+func (t *Tracer[T]) recordLevels(coverSets []coverSet[T]) {
+	t.TotalCoveredSetSize = 0
+
+	for _, cs := range coverSets {
+		t.TotalCoveredSetSize += cs.totalItemCount
+
+		if size := cs.visibleItemCount; size > t.MaxCoverSetSize {
+			t.MaxCoverSetSize = size
+		}
+	}
+
+	t.MaxLevelsTraversed++
+}
+
+func (t *Tracer[T]) removeItem(item T, parent *T, level int) error {
+	if t.ctx == nil {
+		return t.tree.store.RemoveItem(item, parent, level)
+	}
+	return removeItemContext(t.ctx, t.tree.store, item, parent, level)
+}
+
+func (t *Tracer[T]) reset() {
+	t.TotalCoveredSetSize = 0
+	t.MaxCoverSetSize = 0
+	t.MaxLevelsTraversed = 0
+	t.LoadChildrenCount = 0
+	t.TotalLoadChildrenTime = 0
+}
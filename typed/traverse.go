@@ -0,0 +1,98 @@
+package typed
+
+import "fmt"
+
+// traversalItem represents an item to be visited by traverseBreadthFirst,
+// along with the state returned when its parent was visited.
+type traversalItem[T, S any] struct {
+	item   T
+	parent S
+	level  int
+}
+
+// traverseBreadthFirst visits every item in the tree, starting with the roots,
+// then all of their children, then all of their children’s children, and so
+// on. Children are visited in order from their highest to lowest levels.
+//
+// visit is called with each item, the state which visit returned for the
+// item’s parent (or the zero state for roots), and the level at which the item
+// is stored. If visit returns SkipChildren, the children of the item are not
+// visited, and if it returns any other error, the traversal stops and the
+// error is returned.
+//
+// If batchLoads is true, the children of all the items at the same depth are
+// loaded with a single call to the Store, rather than one call per item.
+func traverseBreadthFirst[T, S any](t *Tree[T], tracer *Tracer[T], batchLoads bool, visit func(item T, parent S, level int) (S, error)) error {
+	roots, err := tracer.loadChildren(nil)
+	if err != nil {
+		return err
+	}
+
+	var zero S
+	var pending []traversalItem[T, S]
+	for _, root := range roots[0].itemsAt(t.rootLevel) {
+		pending = append(pending, traversalItem[T, S]{root, zero, t.rootLevel})
+	}
+
+	for len(pending) > 0 {
+		var parents []T
+		var states []S
+
+		for _, ti := range pending {
+			state, err := visit(ti.item, ti.parent, ti.level)
+			if err == SkipChildren {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			parents = append(parents, ti.item)
+			states = append(states, state)
+		}
+
+		children, err := loadChildrenForTraversal(tracer, parents, batchLoads)
+		if err != nil {
+			return err
+		}
+
+		pending = nil
+		for i := range children {
+			for _, level := range children[i].levels() {
+				for _, child := range children[i].itemsAt(level) {
+					pending = append(pending, traversalItem[T, S]{child, states[i], level})
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func loadChildrenForTraversal[T any](tracer *Tracer[T], parents []T, batchLoads bool) ([]LevelsWithItems[T], error) {
+	if len(parents) == 0 {
+		return nil, nil
+	}
+
+	if !batchLoads {
+		children := make([]LevelsWithItems[T], len(parents))
+		for i := range parents {
+			c, err := loadChildrenForTraversal(tracer, parents[i:i+1], true)
+			if err != nil {
+				return nil, err
+			}
+			children[i] = c[0]
+		}
+		return children, nil
+	}
+
+	children, err := tracer.loadChildren(parentsFor(parents)...)
+	if err != nil {
+		return nil, err
+	}
+	if len(children) != len(parents) {
+		return nil, fmt.Errorf("store returned children for %d items instead of %d", len(children), len(parents))
+	}
+
+	return children, nil
+}
@@ -0,0 +1,977 @@
+// Package typed provides cover trees for nearest-neighbour search and
+// clustering of items of a single type, checked at compile time.
+//
+// The covertree package provides the same trees for interface{} items.
+package typed
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Tree represents a single cover tree.
+//
+// Trees should generally not be created except via NewTreeFromStore, and then
+// only by a Store.
+type Tree[T any] struct {
+	basis           float64
+	rootLevel       int
+	distanceBetween DistanceFunc[T]
+	store           Store[T]
+}
+
+// NewTreeWithStore creates and initialises a Tree using the specified store.
+//
+// basis is the logarithmic base for determining the coverage of nodes at each
+// level of the tree.
+//
+// rootDistance is the minimum expected distance between root nodes. New nodes
+// that exceed this distance will be created as additional roots.
+//
+// distanceFunc is the function used by the tree to determine the distance
+// between two items.
+func NewTreeWithStore[T any](store Store[T], basis float64, rootDistance float64, distanceFunc DistanceFunc[T]) (*Tree[T], error) {
+	tree := &Tree[T]{
+		basis:           basis,
+		distanceBetween: distanceFunc,
+		store:           store,
+	}
+	tree.rootLevel = tree.levelForDistance(rootDistance)
+
+	return tree, nil
+}
+
+// CountWithin returns the number of items in the tree which are within the
+// specified distance of the query item.
+//
+// Subtrees which lie entirely within the distance are counted without
+// computing the distances of their items. If the tree’s Store implements
+// SubtreeSizeStore, such subtrees are also counted without being loaded.
+//
+// Multiple calls to CountWithin and Insert are safe to make concurrently.
+func (t *Tree[T]) CountWithin(query T, radius float64) (count int, err error) {
+	return t.countWithinWithTrace(query, radius, t.NewTracer())
+}
+
+// FindFurthest returns the furthest items in the tree from the specified query
+// item, up to the specified maximum number of results, which are at least the
+// specified minimum distance away.
+//
+// Results are returned with their distances from the query item, in order from
+// furthest to closest.
+//
+// If no items are found matching the given criteria, an empty result set is
+// returned.
+//
+// Multiple calls to FindFurthest and Insert are safe to make concurrently.
+func (t *Tree[T]) FindFurthest(query T, maxResults int, minDistance float64) (results []ItemWithDistance[T], err error) {
+	return t.findFurthestWithTrace(query, maxResults, minDistance, t.NewTracer())
+}
+
+// FindNearest returns the nearest items in the tree to the specified query
+// item, up to the specified maximum number of results and maximum distance.
+//
+// Results are returned with their distances from the query item, in order from
+// closest to furthest.
+//
+// If no items are found matching the given criteria, an empty result set is
+// returned.
+//
+// Multiple calls to FindNearest and Insert are safe to make concurrently.
+func (t *Tree[T]) FindNearest(query T, maxResults int, maxDistance float64) (results []ItemWithDistance[T], err error) {
+	return t.findNearestWithTrace(query, maxResults, maxDistance, 0, nil, t.NewTracer())
+}
+
+// FindNearestApprox returns approximate nearest items in the tree to the
+// specified query item, up to the specified maximum number of results and
+// maximum distance.
+//
+// epsilon is the non-negative relative error permitted in the results. The
+// search prunes parts of the tree more aggressively than FindNearest, with the
+// guarantee that the distance of the nth result is within a factor of
+// (1 + epsilon) of the distance of the true nth nearest item. An epsilon of
+// zero gives the same results as FindNearest.
+//
+// An error is returned if epsilon is negative.
+//
+// Results are returned with their distances from the query item, in order from
+// closest to furthest.
+//
+// If no items are found matching the given criteria, an empty result set is
+// returned.
+//
+// Multiple calls to FindNearestApprox and Insert are safe to make concurrently.
+func (t *Tree[T]) FindNearestApprox(query T, maxResults int, maxDistance float64, epsilon float64) (results []ItemWithDistance[T], err error) {
+	return t.findNearestWithTrace(query, maxResults, maxDistance, epsilon, nil, t.NewTracer())
+}
+
+// FindNearestBatch returns the nearest items in the tree to each of the
+// specified query items, up to the specified maximum number of results and
+// maximum distance per query.
+//
+// The queries are traversed through the tree together, so that the children
+// needed by all queries at each level are loaded from the Store with a single
+// call. This is much more efficient than separate calls to FindNearest when
+// loading children is expensive.
+//
+// Results are returned in the same order as the queries. Each set of results
+// contains items with their distances from the corresponding query item, in
+// order from closest to furthest.
+//
+// Multiple calls to FindNearestBatch and Insert are safe to make concurrently.
+func (t *Tree[T]) FindNearestBatch(queries []T, maxResults int, maxDistance float64) (results [][]ItemWithDistance[T], err error) {
+	return t.findNearestBatchWithTrace(queries, maxResults, maxDistance, t.NewTracer())
+}
+
+// FindNearestContext returns the nearest items in the tree to the specified
+// query item, up to the specified maximum number of results and maximum
+// distance.
+//
+// The context is checked between levels of the tree and passed to the Store if
+// it implements ContextStore. If the context is cancelled or its deadline
+// expires before the search is complete, the search is abandoned and the
+// context’s error is returned.
+//
+// Results are returned with their distances from the query item, in order from
+// closest to furthest.
+//
+// If no items are found matching the given criteria, an empty result set is
+// returned.
+//
+// Multiple calls to FindNearestContext and InsertContext are safe to make
+// concurrently.
+func (t *Tree[T]) FindNearestContext(ctx context.Context, query T, maxResults int, maxDistance float64) (results []ItemWithDistance[T], err error) {
+	tracer := t.NewTracer()
+	tracer.ctx = ctx
+	return t.findNearestWithTrace(query, maxResults, maxDistance, 0, nil, tracer)
+}
+
+// FindNearestMatching returns the nearest items in the tree to the specified
+// query item which are accepted by the matches function, up to the specified
+// maximum number of results and maximum distance.
+//
+// Items which are not accepted do not count towards the maximum number of
+// results, but are still traversed so that matching items beneath them can be
+// found.
+//
+// Results are returned with their distances from the query item, in order from
+// closest to furthest.
+//
+// If no items are found matching the given criteria, an empty result set is
+// returned.
+//
+// Multiple calls to FindNearestMatching and Insert are safe to make
+// concurrently.
+func (t *Tree[T]) FindNearestMatching(query T, maxResults int, maxDistance float64, matches MatchFunc[T]) (results []ItemWithDistance[T], err error) {
+	return t.findNearestWithTrace(query, maxResults, maxDistance, 0, matches, t.NewTracer())
+}
+
+// FindReverseNearest returns the items in the tree which would have the
+// specified query item amongst their nearest neighbours, if the query item were
+// to be inserted into the tree.
+//
+// An item is included if fewer than maxNeighbours other items in the tree are
+// closer to it than the query item is. Subtrees whose items are provably closer
+// to enough of their own neighbours are pruned without being fully explored.
+//
+// Results are returned with their distances from the query item, in order from
+// closest to furthest.
+//
+// If no items are found matching the given criteria, an empty result set is
+// returned.
+//
+// Multiple calls to FindReverseNearest and Insert are safe to make
+// concurrently.
+func (t *Tree[T]) FindReverseNearest(query T, maxNeighbours int) (results []ItemWithDistance[T], err error) {
+	return t.findReverseNearestWithTrace(query, maxNeighbours, t.NewTracer())
+}
+
+// FindWithin returns all items in the tree which are within the specified
+// distance of the query item.
+//
+// Results are returned with their distances from the query item, in order from
+// closest to furthest.
+//
+// If no items are found within the given distance, an empty result set is
+// returned.
+//
+// Multiple calls to FindWithin and Insert are safe to make concurrently.
+func (t *Tree[T]) FindWithin(query T, radius float64) (results []ItemWithDistance[T], err error) {
+	return t.findWithinWithTrace(query, radius, t.NewTracer())
+}
+
+// Insert inserts the specified item into the tree.
+//
+// Multiple calls to FindNearest and Insert are safe to make concurrently.
+func (t *Tree[T]) Insert(item T) (err error) {
+	return t.insertWithTrace(item, t.NewTracer())
+}
+
+// KernelDensity returns an estimate of the density of items in the tree around
+// the query item, as the sum of the kernel’s weighting of each item at the
+// specified bandwidth.
+//
+// The built-in kernels weight items at zero distance by one, and the estimate
+// is not normalised, as a metric space has no inherent volume. Dividing by the
+// number of items in the tree gives the average weight per item.
+//
+// Rather than weighting every item individually, subtrees whose items all fall
+// within a narrow enough range of weights are estimated from their covering
+// distance and size, such that the estimate is within tolerance of the exact
+// sum. The tolerance is shared between the roots of the tree, and the share of
+// each subtree which cannot be estimated is split between its children, so
+// the tree’s size need not be known in advance. A tolerance of zero computes
+// the exact sum. If the tree’s Store implements SubtreeSizeStore, estimated
+// subtrees are not loaded.
+//
+// An error is returned if the bandwidth is not positive.
+//
+// Multiple calls to KernelDensity and Insert are safe to make concurrently.
+func (t *Tree[T]) KernelDensity(query T, kernel Kernel, bandwidth float64, tolerance float64) (density float64, err error) {
+	return t.kernelDensityWithTrace(query, kernel, bandwidth, tolerance, t.NewTracer())
+}
+
+// Nearest returns an iterator over the items in the tree in order of their
+// distance from the specified query item, from closest to furthest.
+//
+// Only as much of the tree is loaded as is needed to determine each successive
+// result, so iterating over the first few results of a large tree is cheap.
+//
+// Multiple calls to Nearest, FindNearest and Insert are safe to make
+// concurrently.
+func (t *Tree[T]) Nearest(query T) *NearestIterator[T] {
+	return newNearestIterator(t, query, t.NewTracer())
+}
+
+// InsertContext inserts the specified item into the tree.
+//
+// The context is checked between levels of the tree and passed to the Store if
+// it implements ContextStore. If the context is cancelled or its deadline
+// expires before the item is inserted, the insertion is abandoned and the
+// context’s error is returned.
+//
+// Multiple calls to FindNearestContext and InsertContext are safe to make
+// concurrently.
+func (t *Tree[T]) InsertContext(ctx context.Context, item T) (err error) {
+	tracer := t.NewTracer()
+	tracer.ctx = ctx
+	return t.insertWithTrace(item, tracer)
+}
+
+// NewTracer returns a new Tracer for recording performance metrics for
+// operations on this tree.
+//
+// Tracers are not thread safe and should not be shared by multiple Goroutines.
+func (t *Tree[T]) NewTracer() *Tracer[T] {
+	return &Tracer[T]{tree: t}
+}
+
+// Remove removes the given item from the tree. If no such item exists in the
+// tree, this has no effect.
+//
+// removed will be the item that was successfully removed, and ok reports
+// whether a matching item was found.
+//
+// This method is not safe for concurrent use. Calls to Remove should be
+// externally synchronised so they do not execute concurrently with each other
+// or with calls to FindNearest or Insert.
+func (t *Tree[T]) Remove(item T) (removed T, ok bool, err error) {
+	return t.removeWithTrace(item, t.NewTracer())
+}
+
+// RemoveContext removes the given item from the tree. If no such item exists in
+// the tree, this has no effect.
+//
+// The context is checked between levels of the tree and passed to the Store if
+// it implements ContextStore. If the context is cancelled or its deadline
+// expires before the item is found, the removal is abandoned and the context’s
+// error is returned. Once the item has been removed, the re-parenting of its
+// children is always completed so that they are not lost from the tree.
+//
+// removed will be the item that was successfully removed, and ok reports
+// whether a matching item was found.
+//
+// This method is not safe for concurrent use. Calls to RemoveContext should be
+// externally synchronised so they do not execute concurrently with each other
+// or with calls to other methods.
+func (t *Tree[T]) RemoveContext(ctx context.Context, item T) (removed T, ok bool, err error) {
+	tracer := t.NewTracer()
+	tracer.ctx = ctx
+	return t.removeWithTrace(item, tracer)
+}
+
+// RootDistance returns the minimum expected distance between root nodes of the
+// tree, as would be given to NewTreeWithStore to create the tree. This is
+// useful for recreating trees whose root distance was derived from their
+// items, such as those created by NewTreeFromItems.
+func (t *Tree[T]) RootDistance() float64 {
+	distance := t.distanceForLevel(t.rootLevel)
+
+	// Allow for rounding errors in recovering the level from the distance
+	for t.levelForDistance(distance) > t.rootLevel {
+		distance = math.Nextafter(distance, 0)
+	}
+
+	return distance
+}
+
+// adoptOrphans re-parents each orphan to the first item in the cover set which
+// is within the threshold distance, other than the removed item, returning the
+// orphans which could not be adopted.
+func (t *Tree[T]) adoptOrphans(orphans []T, removed *itemWithChildren[T], parents coverSet[T], distThreshold float64, childLevel int) ([]T, error) {
+	remaining := 0
+
+nextOrphan:
+	for _, item := range orphans {
+		for _, layer := range parents.layers {
+			for i := range layer {
+				parent := &layer[i].withDistance.Item
+				if &layer[i] != removed && t.distanceBetween(item, *parent) <= distThreshold {
+
+					err := t.store.UpdateItem(item, parent, childLevel)
+					if err != nil {
+						return nil, err
+					}
+
+					continue nextOrphan
+				}
+			}
+		}
+
+		orphans[remaining] = item
+		remaining++
+	}
+
+	return orphans[:remaining], nil
+}
+
+func (t *Tree[T]) allItems(tracer *Tracer[T]) (items []T, err error) {
+	err = traverseBreadthFirst(t, tracer, true, func(item T, _ struct{}, _ int) (struct{}, error) {
+		items = append(items, item)
+		return struct{}{}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (t *Tree[T]) countSubtrees(nodes []*node[T], tracer *Tracer[T]) (count int, err error) {
+	sizes, err := t.subtreeSizes(nodes, tracer)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, size := range sizes {
+		count += size
+	}
+	return count, nil
+}
+
+func (t *Tree[T]) countWithinWithTrace(query T, radius float64, tracer *Tracer[T]) (count int, err error) {
+	roots, err := t.loadRootNodes(tracer.loadChildren)
+	if err != nil {
+		return 0, err
+	}
+
+	candidates := t.nodesWithDistance(roots, query)
+
+	for len(candidates) > 0 {
+		var covered []*node[T]
+		var toExpand []nodeWithDistance[T]
+
+		for _, c := range candidates {
+			coverDistance := t.coverDistanceForNode(c.node)
+
+			switch {
+			case c.distance+coverDistance <= radius:
+				if c.node.isLeaf() {
+					count++
+				} else {
+					covered = append(covered, c.node)
+				}
+
+			case c.distance-coverDistance <= radius:
+				toExpand = append(toExpand, c)
+			}
+		}
+
+		if len(covered) > 0 {
+			coveredCount, err := t.countSubtrees(covered, tracer)
+			if err != nil {
+				return 0, err
+			}
+			count += coveredCount
+		}
+
+		candidates, err = t.expandNodesWithDistance(toExpand, query, tracer.loadChildren)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+func (t *Tree[T]) distanceForLevel(level int) float64 {
+	return math.Pow(t.basis, float64(level))
+}
+
+func (t *Tree[T]) findFurthestWithTrace(query T, maxResults int, minDistance float64, tracer *Tracer[T]) (results []ItemWithDistance[T], err error) {
+	roots, err := t.loadRootNodes(tracer.loadChildren)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nodes are explored in order of the furthest distance any of their items
+	// could possibly be from the query
+	var queue priorityQueue[nodeWithDistance[T]]
+	push := func(n nodeWithDistance[T]) {
+		queue.push(n, -(n.distance + t.coverDistanceForNode(n.node)))
+	}
+
+	for _, root := range t.nodesWithDistance(roots, query) {
+		push(root)
+	}
+
+	for len(results) < maxResults && queue.Len() > 0 {
+		n, key := queue.pop()
+		if -key < minDistance {
+			break
+		}
+
+		if n.isLeaf() {
+			results = append(results, ItemWithDistance[T]{n.node.item, n.distance})
+			continue
+		}
+
+		children, err := t.expandNodesWithDistance([]nodeWithDistance[T]{n}, query, tracer.loadChildren)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, child := range children {
+			push(child)
+		}
+	}
+
+	return results, nil
+}
+
+func (t *Tree[T]) findNearestBatchWithTrace(queries []T, maxResults int, maxDistance float64, tracer *Tracer[T]) (results [][]ItemWithDistance[T], err error) {
+	roots, err := tracer.loadChildren(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rootItems := roots[0].itemsAt(t.rootLevel)
+
+	var rootChildren []LevelsWithItems[T]
+	if len(rootItems) > 0 {
+		rootChildren, err = tracer.loadChildren(parentsFor(rootItems)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	coverSets := make([]coverSet[T], len(queries))
+	for i, query := range queries {
+		coverSets[i], err = coverSetWithItems(rootItems, nil, query, t.distanceBetween, func(...*T) ([]LevelsWithItems[T], error) {
+			return cloneLevelsWithItems(rootChildren), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tracer.recordLevels(coverSets)
+
+	promotedChildren := make([][]itemWithChildren[T], len(queries))
+	nextID := len(rootItems)
+
+	for level := t.rootLevel; ; level-- {
+		var parents []*T
+		var parentIDsBySource = make(map[childSource]int)
+		var activeCount = 0
+
+		for i, query := range queries {
+			promotedChildren[i] = nil
+			if coverSets[i].atBottom() {
+				continue
+			}
+
+			distThreshold := t.distanceForLevel(level) + coverSets[i].bound(maxResults, maxDistance, nil)
+			coverSets[i], promotedChildren[i], _ = coverSets[i].promoteChildren(query, distThreshold, level-1, t.distanceBetween)
+			activeCount++
+
+			for j := range promotedChildren[i] {
+				child := &promotedChildren[i][j]
+
+				id, seen := parentIDsBySource[child.source]
+				if !seen {
+					id = nextID + len(parents)
+					parents = append(parents, &child.withDistance.Item)
+					parentIDsBySource[child.source] = id
+				}
+
+				child.id = id
+			}
+		}
+
+		if activeCount == 0 {
+			break
+		}
+
+		if len(parents) > 0 {
+			children, err := tracer.loadChildren(parents...)
+			if err != nil {
+				return nil, err
+			}
+
+			for i := range coverSets {
+				if len(promotedChildren[i]) == 0 {
+					continue
+				}
+
+				grandchildren := make([]LevelsWithItems[T], len(promotedChildren[i]))
+				for j, child := range promotedChildren[i] {
+					grandchildren[j] = children[child.id-nextID].clone()
+				}
+
+				coverSets[i].addPromotedChildren(promotedChildren[i], grandchildren)
+			}
+		}
+
+		nextID += len(parents)
+		tracer.recordLevels(coverSets)
+	}
+
+	results = make([][]ItemWithDistance[T], len(queries))
+	for i := range coverSets {
+		results[i] = coverSets[i].closest(maxResults, maxDistance, nil)
+	}
+
+	return results, nil
+}
+
+func (t *Tree[T]) findNearestWithTrace(query T, maxResults int, maxDistance float64, epsilon float64, matches MatchFunc[T], tracer *Tracer[T]) (results []ItemWithDistance[T], err error) {
+	if epsilon < 0 {
+		return nil, fmt.Errorf("epsilon must be non-negative but was %g", epsilon)
+	}
+
+	cs, err := t.loadRootCoverSet(query, tracer)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer.recordLevel(cs)
+
+	for level := t.rootLevel; !cs.atBottom(); level-- {
+		if err = tracer.contextErr(); err != nil {
+			return nil, err
+		}
+
+		// Shrinking the bound for approximate searches prunes subtrees earlier,
+		// but items already within the bound must remain as candidates
+		bound := cs.bound(maxResults, maxDistance, matches)
+		distThreshold := math.Max(bound, t.distanceForLevel(level)+bound/(1+epsilon))
+
+		cs, _, err = cs.child(query, distThreshold, level-1, t.distanceBetween, tracer.loadChildren)
+		if err != nil {
+			return
+		}
+
+		tracer.recordLevel(cs)
+	}
+
+	return cs.closest(maxResults, maxDistance, matches), nil
+}
+
+func (t *Tree[T]) findReverseNearestWithTrace(query T, maxNeighbours int, tracer *Tracer[T]) (results []ItemWithDistance[T], err error) {
+	roots, err := t.loadRootNodes(tracer.loadChildren)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := t.nodesWithDistance(roots, query)
+
+	for len(candidates) > 0 {
+		var toExpand []nodeWithDistance[T]
+
+		for _, c := range candidates {
+			if c.node.isLeaf() {
+				neighbours, err := t.findNearestWithTrace(c.node.item, maxNeighbours+1, c.distance, 0, nil, tracer)
+				if err != nil {
+					return nil, err
+				}
+
+				// The item itself is amongst its neighbours, and is closer to
+				// itself than to the query unless the two are at zero distance
+				closerCount := 0
+				for _, n := range neighbours {
+					if n.Distance < c.distance {
+						closerCount++
+					}
+				}
+				if c.distance > 0 {
+					closerCount--
+				}
+				if closerCount < maxNeighbours {
+					results = append(results, ItemWithDistance[T]{c.node.item, c.distance})
+				}
+				continue
+			}
+
+			// Every item in the subtree is at least this much further from the
+			// query than from the neighbours of the subtree’s root item, so the
+			// subtree can be pruned if enough neighbours are this close
+			pruneDistance := c.distance - 2*t.coverDistanceForNode(c.node)
+			if pruneDistance > 0 {
+				neighbours, err := t.findNearestWithTrace(c.node.item, maxNeighbours+1, pruneDistance, 0, nil, tracer)
+				if err != nil {
+					return nil, err
+				}
+				if len(neighbours) > maxNeighbours && neighbours[maxNeighbours].Distance < pruneDistance {
+					continue
+				}
+			}
+
+			toExpand = append(toExpand, c)
+		}
+
+		candidates, err = t.expandNodesWithDistance(toExpand, query, tracer.loadChildren)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+
+	return results, nil
+}
+
+func (t *Tree[T]) findWithinWithTrace(query T, radius float64, tracer *Tracer[T]) (results []ItemWithDistance[T], err error) {
+	cs, err := t.loadRootCoverSet(query, tracer)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer.recordLevel(cs)
+
+	for level := t.rootLevel; !cs.atBottom(); level-- {
+		distThreshold := t.distanceForLevel(level) + radius
+
+		cs, _, err = cs.child(query, distThreshold, level-1, t.distanceBetween, tracer.loadChildren)
+		if err != nil {
+			return
+		}
+
+		tracer.recordLevel(cs)
+	}
+
+	return cs.within(radius), nil
+}
+
+func (t *Tree[T]) hoistRootForChild(child T, minChildLevel int, root T, rootLevel int) (newRootLevel, newChildLevel int) {
+	dist := t.distanceBetween(root, child)
+	childLevel := t.levelForDistance(dist)
+	newRootLevel = rootLevel
+
+	if childLevel < minChildLevel {
+		childLevel = minChildLevel
+	}
+	if childLevel >= newRootLevel {
+		newRootLevel = childLevel + 1
+	}
+
+	return newRootLevel, childLevel
+}
+
+func (t *Tree[T]) insert(item T, coverSet coverSet[T], level int, tracer *Tracer[T]) (inserted bool, err error) {
+	if err = tracer.contextErr(); err != nil {
+		return false, err
+	}
+
+	distThreshold := t.distanceForLevel(level)
+
+	childCoverSet, parentWithinThreshold, err := coverSet.child(item, distThreshold, level-1, t.distanceBetween, tracer.loadChildren)
+	if err != nil || childCoverSet.visibleItemCount == 0 {
+		return false, err
+	}
+
+	tracer.recordLevel(childCoverSet)
+
+	// A matching child which is at zero distance - item is a duplicate so insert it as a sibling
+	if layer := childCoverSet.layers[len(childCoverSet.layers)-1]; len(layer) > 0 {
+		if layer[0].withDistance.Distance == 0 {
+
+			if layer[0].parent == nil {
+				err = tracer.addItem(item, nil, level)
+			} else {
+				err = tracer.addItem(item, layer[0].parent, level-1)
+			}
+			return true, err
+		}
+	}
+
+	// Look for a suitable parent amongst the children
+	inserted, err = t.insert(item, childCoverSet, level-1, tracer)
+	if inserted || err != nil {
+		return
+	}
+
+	// No parent was found among the children - pick arbitrary suitable parent at this level
+	if parentWithinThreshold != nil {
+		err = tracer.addItem(item, parentWithinThreshold, level-1)
+		return true, err
+	}
+
+	return false, nil
+}
+
+func (t *Tree[T]) insertWithTrace(item T, tracer *Tracer[T]) error {
+	cs, err := t.loadRootCoverSet(item, tracer)
+	if err != nil {
+		return err
+	}
+
+	tracer.recordLevel(cs)
+
+	var inserted bool
+	inserted, err = t.insert(item, cs, t.rootLevel, tracer)
+	if err == nil && !inserted {
+		return tracer.addItem(item, nil, t.rootLevel)
+	}
+
+	return err
+}
+
+func (t *Tree[T]) kernelDensityWithTrace(query T, kernel Kernel, bandwidth float64, tolerance float64, tracer *Tracer[T]) (density float64, err error) {
+	if bandwidth <= 0 {
+		return 0, fmt.Errorf("bandwidth must be positive but was %g", bandwidth)
+	}
+
+	roots, err := t.loadRootNodes(tracer.loadChildren)
+	if err != nil {
+		return 0, err
+	}
+
+	// Each candidate has a share of the tolerance within which its subtree may
+	// be estimated. The roots share the tolerance equally, and the share of
+	// each expanded node is split equally between its children.
+	candidates := t.nodesWithDistance(roots, query)
+	tolerances := make([]float64, len(candidates))
+	for i := range tolerances {
+		tolerances[i] = tolerance / float64(len(roots))
+	}
+
+	for len(candidates) > 0 {
+		var estimable []*node[T]
+		var estimableIndices []int
+		var weights, errorBounds []float64
+		var toExpand []int
+
+		for i, c := range candidates {
+			if c.node.isLeaf() {
+				density += kernel(c.distance, bandwidth)
+				continue
+			}
+
+			coverDistance := t.coverDistanceForNode(c.node)
+			maxWeight := kernel(math.Max(0, c.distance-coverDistance), bandwidth)
+			minWeight := kernel(c.distance+coverDistance, bandwidth)
+
+			switch {
+			case maxWeight == 0:
+				// Nothing in the subtree contributes
+
+			case maxWeight-minWeight <= tolerances[i]:
+				// Subtrees have at least two items, so any with a wider range
+				// of weights cannot be estimated within their tolerance
+				estimable = append(estimable, c.node)
+				estimableIndices = append(estimableIndices, i)
+				weights = append(weights, (maxWeight+minWeight)/2)
+				errorBounds = append(errorBounds, (maxWeight-minWeight)/2)
+
+			default:
+				toExpand = append(toExpand, i)
+			}
+		}
+
+		if len(estimable) > 0 {
+			sizes, err := t.subtreeSizes(estimable, tracer)
+			if err != nil {
+				return 0, err
+			}
+
+			for j, size := range sizes {
+				if float64(size)*errorBounds[j] <= tolerances[estimableIndices[j]] {
+					density += float64(size) * weights[j]
+				} else {
+					toExpand = append(toExpand, estimableIndices[j])
+				}
+			}
+		}
+
+		expanding := make([]nodeWithDistance[T], len(toExpand))
+		var childTolerances []float64
+
+		for j, i := range toExpand {
+			expanding[j] = candidates[i]
+
+			// The node’s own item is weighted exactly, so its share of the
+			// tolerance is given to its children
+			n := candidates[i].node
+			childCount := 0
+			for _, level := range n.childLevels {
+				childCount += len(n.children.itemsAt(level))
+			}
+
+			childTolerances = append(childTolerances, 0)
+			for k := 0; k < childCount; k++ {
+				childTolerances = append(childTolerances, tolerances[i]/float64(childCount))
+			}
+		}
+
+		candidates, err = t.expandNodesWithDistance(expanding, query, tracer.loadChildren)
+		if err != nil {
+			return 0, err
+		}
+		tolerances = childTolerances
+	}
+
+	return density, nil
+}
+
+func (t *Tree[T]) levelForDistance(distance float64) int {
+	return int(math.Ceil(math.Log2(distance) / math.Log2(t.basis)))
+}
+
+func (t *Tree[T]) loadRootCoverSet(query T, tracer *Tracer[T]) (coverSet[T], error) {
+	roots, err := tracer.loadChildren(nil)
+	if err != nil {
+		return coverSet[T]{}, err
+	}
+
+	return coverSetWithItems(roots[0].itemsAt(t.rootLevel), nil, query, t.distanceBetween, tracer.loadChildren)
+}
+
+func (t *Tree[T]) remove(item T, coverSet coverSet[T], level int, tracer *Tracer[T]) (removed T, ok bool, orphans []T, err error) {
+	for _, layer := range coverSet.layers {
+		for i := range layer {
+			if layer[i].withDistance.Distance == 0 {
+				err = tracer.removeItem(layer[i].withDistance.Item, layer[i].parent, level)
+				if err != nil {
+					return
+				}
+				removed, ok = layer[i].withDistance.Item, true
+
+				for _, child := range layer[i].children.items {
+					orphans = append(orphans, child...)
+				}
+
+				// Try to get orphans adopted by one of the siblings of the deleted node
+				orphans, err = t.adoptOrphans(orphans, &layer[i], coverSet, t.distanceForLevel(level-1), level-1)
+
+				break
+			}
+		}
+	}
+
+	if !ok {
+		if coverSet.atBottom() {
+			return
+		}
+
+		if err = tracer.contextErr(); err != nil {
+			return removed, false, nil, err
+		}
+
+		distThreshold := t.distanceForLevel(level)
+		childCoverSet, _, err := coverSet.child(item, distThreshold, level-1, t.distanceBetween, tracer.loadChildren)
+		if err != nil {
+			return removed, false, nil, err
+		}
+		tracer.recordLevel(childCoverSet)
+
+		removed, ok, orphans, err = t.remove(item, childCoverSet, level-1, tracer)
+	}
+
+	return
+}
+
+func (t *Tree[T]) removeWithTrace(item T, tracer *Tracer[T]) (removed T, ok bool, err error) {
+	cs, err := t.loadRootCoverSet(item, tracer)
+	if err != nil {
+		return removed, false, err
+	}
+
+	tracer.recordLevel(cs)
+
+	var orphans []T
+	removed, ok, orphans, err = t.remove(item, cs, t.rootLevel, tracer)
+	if err == nil {
+		for _, orphan := range orphans {
+			err = t.store.UpdateItem(orphan, nil, t.rootLevel)
+			if err != nil {
+				return removed, ok, err
+			}
+		}
+	}
+
+	return
+}
+
+// subtreeSizes returns the number of items in the subtree of each node,
+// including the node itself. If the tree’s Store implements SubtreeSizeStore,
+// the sizes are loaded directly; otherwise, the subtrees are loaded and counted
+// without computing any distances.
+func (t *Tree[T]) subtreeSizes(nodes []*node[T], tracer *Tracer[T]) ([]int, error) {
+	if sizeStore, ok := t.store.(SubtreeSizeStore[T]); ok {
+		items := make([]T, len(nodes))
+		for i := range nodes {
+			items[i] = nodes[i].item
+		}
+
+		return sizeStore.LoadSubtreeSizes(items...)
+	}
+
+	sizes := make([]int, len(nodes))
+
+	// Track which of the original nodes each descendant belongs to
+	owners := make([]int, len(nodes))
+	for i := range owners {
+		owners[i] = i
+	}
+
+	for len(nodes) > 0 {
+		for _, owner := range owners {
+			sizes[owner]++
+		}
+
+		err := t.expandNodes(nodes, tracer.loadChildren)
+		if err != nil {
+			return nil, err
+		}
+
+		var children []*node[T]
+		var childOwners []int
+		for i, n := range nodes {
+			if n.isLeaf() {
+				continue
+			}
+			for _, child := range n.expanded[1:] {
+				children = append(children, child)
+				childOwners = append(childOwners, owners[i])
+			}
+		}
+		nodes, owners = children, childOwners
+	}
+
+	return sizes, nil
+}
@@ -0,0 +1,1375 @@
+package typed
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func BenchmarkTree(b *testing.B) {
+
+	b.Run("FindNearest()", func(b *testing.B) {
+		rand.Seed(123)
+
+		cases := []int{
+			100,
+			100000,
+		}
+
+		for _, pointCount := range cases {
+
+			b.Run(fmt.Sprintf("with tree of size %d", pointCount), func(b *testing.B) {
+
+				constraintCases := []struct {
+					Description string
+					MaxResults  int
+					MaxDistance float64
+				}{
+					{Description: "exact single match", MaxResults: 1, MaxDistance: 0.0},
+					{Description: "many exact matches", MaxResults: 1024, MaxDistance: 0.0},
+					{Description: "nearest single match", MaxResults: 1, MaxDistance: math.MaxFloat64},
+					{Description: "nearest of some close matches", MaxResults: 128, MaxDistance: 50.0},
+					{Description: "nearest of some distant matches", MaxResults: 128, MaxDistance: 500.0},
+					{Description: "nearest of many distant matches", MaxResults: 1024, MaxDistance: 500.0},
+					{Description: "nearest of many matches at any distance", MaxResults: 1024, MaxDistance: math.MaxFloat64},
+				}
+
+				tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+				_, _ = insertPoints(randomPoints(pointCount), tree)
+
+				for _, constraint := range constraintCases {
+
+					b.Run(constraint.Description, func(b *testing.B) {
+						b.StopTimer()
+
+						for i := 0; i < b.N; i++ {
+							p := randomPoint()
+
+							b.StartTimer()
+							_, _ = tree.FindNearest(&p, constraint.MaxResults, constraint.MaxDistance)
+							b.StopTimer()
+
+							_, _, _ = tree.Remove(&p)
+						}
+					})
+				}
+			})
+		}
+	})
+
+	b.Run("Insert()", func(b *testing.B) {
+		rand.Seed(123)
+
+		cases := []int{
+			100,
+			1000,
+			10000,
+			100000,
+		}
+
+		for _, pointCount := range cases {
+
+			b.Run(fmt.Sprintf("with tree of size %d", pointCount), func(b *testing.B) {
+				b.StopTimer()
+				tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+				_, _ = insertPoints(randomPoints(pointCount), tree)
+
+				for i := 0; i < b.N; i++ {
+					p := randomPoint()
+
+					b.StartTimer()
+					_ = tree.Insert(&p)
+					b.StopTimer()
+
+					_, _, _ = tree.Remove(&p)
+				}
+			})
+		}
+	})
+}
+
+func TestTree(t *testing.T) {
+
+	seed := time.Now().UnixNano()
+	fmt.Println("Seed:", seed)
+	rand.Seed(seed)
+
+	t.Run("CountWithin()", func(t *testing.T) {
+
+		t.Run("returns zero for empty tree", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			query := randomPoint()
+			count, err := tree.CountWithin(&query, math.MaxFloat64)
+
+			if err != nil {
+				t.Fatalf("Expected count to succeed but got error: %v", err)
+			}
+			if expected, actual := 0, count; expected != actual {
+				t.Errorf("Expected count of %d but got %d", expected, actual)
+			}
+		})
+	})
+
+	t.Run("FindFurthest()", func(t *testing.T) {
+
+		t.Run("returns no results for empty tree", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			query := randomPoint()
+			results, err := tree.FindFurthest(&query, 16, 0)
+
+			if err != nil {
+				t.Fatalf("Expected search to succeed but got error: %v", err)
+			}
+			if expected, actual := 0, len(results); expected != actual {
+				t.Errorf("Expected no results but got %d", actual)
+			}
+		})
+
+		t.Run("with a populated tree", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			points := []Point{
+				{1.0, 0.0, 0.0},
+				{2.0, 0.0, 0.0},
+				{3.0, 0.0, 0.0},
+			}
+			_, _ = insertPoints(points, tree)
+
+			t.Run("returns up to the maximum requested results from furthest to closest", func(t *testing.T) {
+				query := Point{0.0, 0.0, 0.0}
+				results, err := tree.FindFurthest(&query, 2, 0)
+
+				if err != nil {
+					t.Fatalf("Expected search to succeed but got error: %v", err)
+				}
+				expectSameResults(t, query, results, []ItemWithDistance[*Point]{
+					{&points[2], distanceBetweenPoints(&points[2], &query)},
+					{&points[1], distanceBetweenPoints(&points[1], &query)},
+				})
+			})
+
+			t.Run("returns results down to the minimum requested distance", func(t *testing.T) {
+				query := Point{0.0, 0.0, 0.0}
+				results, err := tree.FindFurthest(&query, 3, 2.5)
+
+				if err != nil {
+					t.Fatalf("Expected search to succeed but got error: %v", err)
+				}
+				expectSameResults(t, query, results, []ItemWithDistance[*Point]{
+					{&points[2], distanceBetweenPoints(&points[2], &query)},
+				})
+			})
+		})
+	})
+
+	t.Run("FindNearest()", func(t *testing.T) {
+
+		t.Run("returns no results for empty tree", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			query := randomPoint()
+			results, err := tree.FindNearest(&query, 16, math.MaxFloat64)
+
+			if err != nil {
+				t.Fatalf("Expected search to succeed but got error: %v", err)
+			}
+			if expected, actual := 0, len(results); expected != actual {
+				t.Errorf("Expected no results but got %d", actual)
+			}
+		})
+
+		t.Run("returns the only result for tree with a single root node", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			p := randomPoint()
+			_ = tree.Insert(&p)
+
+			query := randomPoint()
+			results, err := tree.FindNearest(&query, 2, math.MaxFloat64)
+
+			if err != nil {
+				t.Fatalf("Expected search to succeed but got error: %v", err)
+			}
+			expectSameResults(t, query, results, []ItemWithDistance[*Point]{{&p, distanceBetweenPoints(&p, &query)}})
+		})
+
+		t.Run("with a populated tree", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			points := []Point{
+				{1.0, 0.0, 0.0},
+				{2.0, 0.0, 0.0},
+				{3.0, 0.0, 0.0},
+			}
+			_, _ = insertPoints(points, tree)
+
+			t.Run("returns available results when less than the maximum requested", func(t *testing.T) {
+				query := Point{0.0, 0.0, 0.0}
+				results, err := tree.FindNearest(&query, 5, math.MaxFloat64)
+
+				if err != nil {
+					t.Fatalf("Expected search to succeed but got error: %v", err)
+				}
+				expectSameResults(t, query, results, []ItemWithDistance[*Point]{
+					{&points[0], distanceBetweenPoints(&points[0], &query)},
+					{&points[1], distanceBetweenPoints(&points[1], &query)},
+					{&points[2], distanceBetweenPoints(&points[2], &query)},
+				})
+			})
+
+			t.Run("returns up to the maximum requested results", func(t *testing.T) {
+				query := Point{0.0, 0.0, 0.0}
+				results, err := tree.FindNearest(&query, 2, math.MaxFloat64)
+
+				if err != nil {
+					t.Fatalf("Expected search to succeed but got error: %v", err)
+				}
+				expectSameResults(t, query, results, []ItemWithDistance[*Point]{
+					{&points[0], distanceBetweenPoints(&points[0], &query)},
+					{&points[1], distanceBetweenPoints(&points[1], &query)},
+				})
+			})
+
+			t.Run("returns results up to the maximum requested distance", func(t *testing.T) {
+				query := Point{0.0, 0.0, 0.0}
+				results, err := tree.FindNearest(&query, 3, 2.0)
+
+				if err != nil {
+					t.Fatalf("Expected search to succeed but got error: %v", err)
+				}
+				expectSameResults(t, query, results, []ItemWithDistance[*Point]{
+					{&points[0], distanceBetweenPoints(&points[0], &query)},
+					{&points[1], distanceBetweenPoints(&points[1], &query)},
+				})
+			})
+		})
+	})
+
+	t.Run("FindNearestBatch()", func(t *testing.T) {
+
+		t.Run("returns no results for empty tree", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			query1, query2 := randomPoint(), randomPoint()
+			results, err := tree.FindNearestBatch([]*Point{&query1, &query2}, 16, math.MaxFloat64)
+
+			if err != nil {
+				t.Fatalf("Expected search to succeed but got error: %v", err)
+			}
+			if expected, actual := 2, len(results); expected != actual {
+				t.Fatalf("Expected %d result sets but got %d", expected, actual)
+			}
+			for i := range results {
+				if expected, actual := 0, len(results[i]); expected != actual {
+					t.Errorf("Expected no results for query %d but got %d", i, actual)
+				}
+			}
+		})
+	})
+
+	t.Run("FindNearestContext()", func(t *testing.T) {
+
+		t.Run("passes the context to a context-aware store", func(t *testing.T) {
+			store := newContextRecordingStore(distanceBetweenPoints)
+			tree, _ := NewTreeWithStore[*Point](store, 2, 1000.0, distanceBetweenPoints)
+			_, _ = insertPoints(randomPoints(10), tree)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			query := randomPoint()
+			results, err := tree.FindNearestContext(ctx, &query, 4, math.MaxFloat64)
+
+			if err != nil {
+				t.Fatalf("Expected search to succeed but got error: %v", err)
+			}
+			if expected, actual := 4, len(results); expected != actual {
+				t.Errorf("Expected %d results but got %d", expected, actual)
+			}
+			store.expectOnlyContext(t, ctx)
+		})
+
+		t.Run("returns the context error when cancelled", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			_, _ = insertPoints(randomPoints(10), tree)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			query := randomPoint()
+			results, err := tree.FindNearestContext(ctx, &query, 4, math.MaxFloat64)
+
+			if expected, actual := context.Canceled, err; expected != actual {
+				t.Errorf("Expected error %v but got %v", expected, actual)
+			}
+			if results != nil {
+				t.Errorf("Expected no results but got %v", results)
+			}
+		})
+	})
+
+	t.Run("FindWithin()", func(t *testing.T) {
+
+		t.Run("returns no results for empty tree", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			query := randomPoint()
+			results, err := tree.FindWithin(&query, math.MaxFloat64)
+
+			if err != nil {
+				t.Fatalf("Expected search to succeed but got error: %v", err)
+			}
+			if expected, actual := 0, len(results); expected != actual {
+				t.Errorf("Expected no results but got %d", actual)
+			}
+		})
+
+		t.Run("with a populated tree", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			points := []Point{
+				{1.0, 0.0, 0.0},
+				{2.0, 0.0, 0.0},
+				{3.0, 0.0, 0.0},
+			}
+			_, _ = insertPoints(points, tree)
+
+			t.Run("returns all results within the requested distance", func(t *testing.T) {
+				query := Point{0.0, 0.0, 0.0}
+				results, err := tree.FindWithin(&query, 2.0)
+
+				if err != nil {
+					t.Fatalf("Expected search to succeed but got error: %v", err)
+				}
+				expectSameResults(t, query, results, []ItemWithDistance[*Point]{
+					{&points[0], distanceBetweenPoints(&points[0], &query)},
+					{&points[1], distanceBetweenPoints(&points[1], &query)},
+				})
+			})
+
+			t.Run("returns all results when the distance covers the tree", func(t *testing.T) {
+				query := Point{0.0, 0.0, 0.0}
+				results, err := tree.FindWithin(&query, math.MaxFloat64)
+
+				if err != nil {
+					t.Fatalf("Expected search to succeed but got error: %v", err)
+				}
+				expectSameResults(t, query, results, []ItemWithDistance[*Point]{
+					{&points[0], distanceBetweenPoints(&points[0], &query)},
+					{&points[1], distanceBetweenPoints(&points[1], &query)},
+					{&points[2], distanceBetweenPoints(&points[2], &query)},
+				})
+			})
+		})
+	})
+
+	t.Run("Insert()", func(t *testing.T) {
+
+		t.Run("inserts duplicates of the root as sibling roots", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			store := tree.store.(*inMemoryStore[*Point])
+
+			p1 := randomPoint()
+			err := tree.Insert(&p1)
+			if err != nil {
+				t.Fatalf("Error inserting point into tree: %v", err)
+			}
+
+			p2 := p1
+			err = tree.Insert(&p2)
+			if err != nil {
+				t.Fatalf("Error inserting point into tree: %v", err)
+			}
+
+			nodeCount := traverseTree(tree, store, false)
+
+			if expected, actual := 2, nodeCount; expected != actual {
+				t.Errorf("Expected %d nodes in tree after inserting duplicate but found %d", expected, actual)
+			}
+
+			levels := store.levelsFor(nil)
+			if expected, actual := 2, len(levels[tree.rootLevel]); expected != actual {
+				t.Errorf("Expected %d roots but got %d", expected, actual)
+			}
+
+			found, err := tree.FindNearest(&p2, 2, 0.0)
+			if err != nil {
+				t.Fatalf("Expected lookup of duplicates to succeed but got error: %v", err)
+			}
+			if expected, actual := 2, len(found); expected != actual {
+				t.Errorf("Expected %d duplicate items to be findable but found %d instead", expected, actual)
+			} else {
+				if expected, actual := &p1, found[0].Item; expected != actual {
+					t.Errorf("Expected first inserted duplicate to be findable but got %v", actual)
+				}
+				if expected, actual := &p2, found[1].Item; expected != actual {
+					t.Errorf("Expected second inserted duplicate to be findable but got %v", actual)
+				}
+			}
+		})
+
+		t.Run("inserts duplicates as siblings of the original item", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			store := tree.store.(*inMemoryStore[*Point])
+
+			_, _ = insertPoints(randomPoints(2), tree)
+
+			p1 := randomPoint()
+			err := tree.Insert(&p1)
+			if err != nil {
+				t.Fatalf("Error inserting point into tree: %v", err)
+			}
+
+			p2 := p1
+			err = tree.Insert(&p2)
+			if err != nil {
+				t.Fatalf("Error inserting point into tree: %v", err)
+			}
+
+			nodeCount := traverseTree(tree, store, false)
+
+			if expected, actual := 4, nodeCount; expected != actual {
+				t.Errorf("Expected %d nodes in tree after inserting duplicate but found %d", expected, actual)
+			}
+
+			// Duplicate items should not be children of each other (they should
+			// have no children as they were inserted last).
+			parents := []*Point{&p1, &p2}
+			children, err := store.LoadChildren(&parents[0], &parents[1])
+			if err != nil {
+				t.Fatalf("Expected to be able to load children but got error: %v", err)
+			}
+			if expected, actual := 2, len(children); expected != actual {
+				t.Errorf("Expected %d entries for children but got %d", expected, actual)
+			} else {
+				for i := range children {
+					if expected, actual := 0, len(children[i].items); expected != actual {
+						t.Errorf("Expected no children for item %d but found %d", i, actual)
+					}
+				}
+			}
+
+			found, err := tree.FindNearest(&p2, 2, 0.0)
+			if err != nil {
+				t.Fatalf("Expected lookup of duplicates to succeed but got error: %v", err)
+			}
+			if expected, actual := 2, len(found); expected != actual {
+				t.Errorf("Expected %d duplicate items to be findable but found %d instead", expected, actual)
+			} else {
+				if expected, actual := &p1, found[0].Item; expected != actual {
+					t.Errorf("Expected first inserted duplicate to be findable but got %v", actual)
+				}
+				if expected, actual := &p2, found[1].Item; expected != actual {
+					t.Errorf("Expected second inserted duplicate to be findable but got %v", actual)
+				}
+			}
+		})
+
+		t.Run("saves the tree root state when it changes", func(t *testing.T) {
+			store := newTestStore(distanceBetweenPoints)
+			tree, _ := NewTreeWithStore[*Point](store, 2, 10.0, distanceBetweenPoints)
+
+			// First point should become the initial root at the level for the specified rootDistance
+			p1 := &Point{1.0, 0.0, 0.0}
+			err := tree.Insert(p1)
+
+			if err != nil {
+				t.Fatalf("Expected insert to succeed but got error: %v", err)
+			}
+			store.expectSavedTree(t, 1, []*Point{p1}, tree.rootLevel)
+
+			// Second point should be inserted as a child
+			p2 := &Point{2.0, 0.0, 0.0}
+			err = tree.Insert(p2)
+
+			if err != nil {
+				t.Fatalf("Expected insert to succeed but got error: %v", err)
+			}
+			store.expectSavedTree(t, 2, []*Point{p1}, tree.rootLevel)
+
+			// Third point is very different and should cause a second root to be added
+			p3 := &Point{100.0, 0.0, 0.0}
+			err = tree.Insert(p3)
+
+			if err != nil {
+				t.Fatalf("Expected insert to succeed but got error: %v", err)
+			}
+			store.expectSavedTree(t, 3, []*Point{p1, p3}, 8)
+
+			// Fourth point is a new child and should deepen the tree a little
+			p4 := &Point{1.1, 0.0, 0.0}
+			err = tree.Insert(p4)
+
+			if err != nil {
+				t.Fatalf("Expected insert to succeed but got error: %v", err)
+			}
+			store.expectSavedTree(t, 4, []*Point{p1, p3}, 8)
+
+			// Fifth point is another new child at the same depth
+			p5 := &Point{2.1, 0.0, 0.0}
+			err = tree.Insert(p5)
+
+			if err != nil {
+				t.Fatalf("Expected insert to succeed but got error: %v", err)
+			}
+			store.expectSavedTree(t, 5, []*Point{p1, p3}, 8)
+		})
+
+		t.Run("is thread-safe with concurrent reads", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			points := randomPoints(10000)
+
+			const workers = 8
+			var insertQueue = make(chan *Point, workers*2)
+			var doneGroup sync.WaitGroup
+			doneGroup.Add(workers)
+
+			for i := 0; i < workers; i++ {
+				go func() {
+					for p := range insertQueue {
+						_ = tree.Insert(p)
+						_, _ = tree.FindNearest(p, 1, 0.0)
+					}
+					doneGroup.Done()
+				}()
+			}
+
+			for i := range points {
+				insertQueue <- &points[i]
+			}
+			close(insertQueue)
+			doneGroup.Wait()
+
+			for i := range points {
+				p := &points[i]
+
+				results, err := tree.FindNearest(p, 1, 0.0)
+
+				if err != nil {
+					t.Fatalf("Expected success looking up point but got error: %v", err)
+				}
+
+				if len(results) == 0 {
+					t.Errorf("Expected point %v to be findable but wasn’t", p)
+				} else if results[0].Item != p {
+					t.Errorf("Expected point %v to be findable but found %v instead", p, results[0].Item)
+				}
+			}
+		})
+	})
+
+	t.Run("InsertContext()", func(t *testing.T) {
+
+		t.Run("passes the context to a context-aware store", func(t *testing.T) {
+			store := newContextRecordingStore(distanceBetweenPoints)
+			tree, _ := NewTreeWithStore[*Point](store, 2, 1000.0, distanceBetweenPoints)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			points := randomPoints(10)
+			for i := range points {
+				err := tree.InsertContext(ctx, &points[i])
+				if err != nil {
+					t.Fatalf("Expected insert to succeed but got error: %v", err)
+				}
+			}
+
+			store.expectOnlyContext(t, ctx)
+			if expected, actual := len(points), traverseTree(tree, &store.inMemoryStore, false); expected != actual {
+				t.Errorf("Expected %d nodes in tree but found %d", expected, actual)
+			}
+		})
+
+		t.Run("does not insert the item when cancelled", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			_, _ = insertPoints(randomPoints(10), tree)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			p := randomPoint()
+			err := tree.InsertContext(ctx, &p)
+
+			if expected, actual := context.Canceled, err; expected != actual {
+				t.Errorf("Expected error %v but got %v", expected, actual)
+			}
+			if expected, actual := 10, traverseTree(tree, tree.store.(*inMemoryStore[*Point]), false); expected != actual {
+				t.Errorf("Expected %d nodes in tree but found %d", expected, actual)
+			}
+		})
+	})
+
+	t.Run("KernelDensity()", func(t *testing.T) {
+
+		t.Run("returns zero for empty tree", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			query := randomPoint()
+			density, err := tree.KernelDensity(&query, GaussianKernel, 100, 0)
+
+			if err != nil {
+				t.Fatalf("Expected density to succeed but got error: %v", err)
+			}
+			if expected, actual := 0.0, density; expected != actual {
+				t.Errorf("Expected density of %g but got %g", expected, actual)
+			}
+		})
+
+		t.Run("returns an error when the bandwidth is not positive", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+			_, _ = insertPoints(randomPoints(10), tree)
+
+			for _, bandwidth := range []float64{0, -1} {
+				query := randomPoint()
+
+				_, err := tree.KernelDensity(&query, GaussianKernel, bandwidth, 0)
+
+				if err == nil {
+					t.Errorf("Expected an error for bandwidth %g but got none", bandwidth)
+				}
+			}
+		})
+	})
+
+	t.Run("Remove()", func(t *testing.T) {
+
+		t.Run("has no effect when the tree is empty", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			p := randomPoint()
+			removed, ok, err := tree.Remove(&p)
+
+			if err != nil {
+				t.Errorf("Expected removal to have no effect but got error: %v", err)
+			}
+			if ok {
+				t.Errorf("Expected no item to have been found")
+			}
+			if removed != nil {
+				t.Errorf("Expected nothing to have been removed but got %v", removed)
+			}
+		})
+
+		t.Run("removes item from the tree while preserving its children", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			points := []Point{
+				{1.0, 0.0, 0.0},
+				{1.1, 0.0, 0.0},
+				{1.11, 0.0, 0.0},
+				{1.111, 0.0, 0.0},
+			}
+			_, _ = insertPoints(points, tree)
+
+			removed, _, _ := tree.Remove(&points[2])
+
+			if expected, actual := &points[2], removed; expected != actual {
+				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
+			}
+
+			nodeCount := traverseTree(tree, tree.store.(*inMemoryStore[*Point]), false)
+			if expected, actual := len(points)-1, nodeCount; expected != actual {
+				t.Errorf("Expected %d nodes remaining after removal but found %d", expected, actual)
+			}
+
+			// Removed node should no longer be findable
+			results, _ := tree.FindNearest(&points[2], 1, 0)
+			expectSameResults(t, points[2], results, nil)
+
+			// Orphaned child node should have been re-parented and still be findable
+			results, _ = tree.FindNearest(&points[3], 1, 0)
+			expectSameResults(t, points[3], results, []ItemWithDistance[*Point]{{&points[3], 0}})
+
+			removed, _, _ = tree.Remove(&points[1])
+
+			if expected, actual := &points[1], removed; expected != actual {
+				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
+			}
+
+			nodeCount = traverseTree(tree, tree.store.(*inMemoryStore[*Point]), false)
+			if expected, actual := len(points)-2, nodeCount; expected != actual {
+				t.Errorf("Expected %d nodes remaining after removal but found %d", expected, actual)
+			}
+
+			// Removed node should no longer be findable
+			results, _ = tree.FindNearest(&points[1], 1, 0)
+			expectSameResults(t, points[1], results, nil)
+
+			// Orphaned child node should have been re-parented and still be findable
+			results, _ = tree.FindNearest(&points[3], 1, 0)
+			expectSameResults(t, points[3], results, []ItemWithDistance[*Point]{{&points[3], 0}})
+
+			removed, _, _ = tree.Remove(&points[0])
+
+			if expected, actual := &points[0], removed; expected != actual {
+				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
+			}
+
+			nodeCount = traverseTree(tree, tree.store.(*inMemoryStore[*Point]), false)
+			if expected, actual := len(points)-3, nodeCount; expected != actual {
+				t.Errorf("Expected %d nodes remaining after removal but found %d", expected, actual)
+			}
+
+			// Removed node should no longer be findable
+			results, _ = tree.FindNearest(&points[0], 1, 0)
+			expectSameResults(t, points[0], results, nil)
+
+			// Orphaned child node should have been re-parented and still be findable
+			results, _ = tree.FindNearest(&points[3], 1, 0)
+			expectSameResults(t, points[3], results, []ItemWithDistance[*Point]{{&points[3], 0}})
+
+			removed, _, _ = tree.Remove(&points[3])
+
+			if expected, actual := &points[3], removed; expected != actual {
+				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
+			}
+
+			nodeCount = traverseTree(tree, tree.store.(*inMemoryStore[*Point]), false)
+			if expected, actual := len(points)-4, nodeCount; expected != actual {
+				t.Errorf("Expected %d nodes remaining after removal but found %d", expected, actual)
+			}
+
+			// Removed node should no longer be findable (tree is now empty)
+			results, _ = tree.FindNearest(&points[3], 1, 0)
+			expectSameResults(t, points[3], results, nil)
+		})
+
+		t.Run("correctly re-parents orphans that are no longer covered by the root", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			points := []Point{
+				{0.0, 0.0, 0.0},
+				{16.0, 0.0, 0.0},
+				{15.0, 0.0, 6.0},
+			}
+			_, _ = insertPoints(points, tree)
+
+			root, rootLevel, _ := loadRoot(tree)
+
+			if expected, actual := &points[0], root; expected != actual {
+				t.Errorf("Expected root node to be %v before removal but was %v", expected, actual)
+			}
+			if expected, actual := 10, rootLevel; expected != actual {
+				t.Errorf("Expected root to be at level %d but was %d", expected, actual)
+			}
+
+			removed, _, _ := tree.Remove(&points[1])
+
+			if expected, actual := &points[1], removed; expected != actual {
+				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
+			}
+
+			root, rootLevel, _ = loadRoot(tree)
+
+			nodeCount := traverseTree(tree, tree.store.(*inMemoryStore[*Point]), false)
+			if expected, actual := len(points)-1, nodeCount; expected != actual {
+				t.Fatalf("Expected %d nodes remaining after removal but found %d", expected, actual)
+			}
+
+			if expected, actual := &points[0], root; expected != actual {
+				t.Errorf("Expected root node to be %v after removal but was %v", expected, actual)
+			}
+			if expected, actual := 10, rootLevel; expected != actual {
+				t.Errorf("Expected root to be at level %d but was %d", expected, actual)
+			}
+
+			// Removed node should no longer be findable
+			results, _ := tree.FindNearest(&points[1], 1, 0)
+			expectSameResults(t, points[1], results, nil)
+
+			// Remaining nodes should still be findable
+			results, _ = tree.FindNearest(&points[0], 1, 0)
+			expectSameResults(t, points[0], results, []ItemWithDistance[*Point]{{&points[0], 0}})
+			results, _ = tree.FindNearest(&points[2], 1, 0)
+			expectSameResults(t, points[2], results, []ItemWithDistance[*Point]{{&points[2], 0}})
+		})
+
+		t.Run("saves the tree root state when it changes", func(t *testing.T) {
+			store := newTestStore(distanceBetweenPoints)
+			tree, _ := NewTreeWithStore[*Point](store, 2, 1000.0, distanceBetweenPoints)
+
+			points := []Point{
+				{0.0, 0.0, 0.0},
+				{16.0, 0.0, 0.0},
+				{15.0, 0.0, 1.0},
+				{1.0, 0.0, 0.0},
+			}
+			_, _ = insertPoints(points, tree)
+
+			store.savedCount = 0
+			store.expectSavedTree(t, 0, []*Point{&points[0]}, 4)
+
+			// Removing parent node should cause its uncovered child to be made a new root
+			removed, _, err := tree.Remove(&points[1])
+			if err != nil {
+				t.Fatalf("Expected removal to succeed but got error: %v", err)
+			}
+			if expected, actual := &points[1], removed; expected != actual {
+				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
+			}
+			store.expectSavedTree(t, 2, []*Point{&points[0], &points[2]}, 5)
+
+			// Removing leaf node should not affect roots
+			removed, _, err = tree.Remove(&points[3])
+			if err != nil {
+				t.Fatalf("Expected removal to succeed but got error: %v", err)
+			}
+			if expected, actual := &points[3], removed; expected != actual {
+				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
+			}
+			store.expectSavedTree(t, 3, []*Point{&points[0], &points[2]}, 5)
+
+			// Removing root node should cause remaining root to become the only root
+			removed, _, err = tree.Remove(&points[0])
+			if err != nil {
+				t.Fatalf("Expected removal to succeed but got error: %v", err)
+			}
+			if expected, actual := &points[0], removed; expected != actual {
+				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
+			}
+			store.expectSavedTree(t, 4, []*Point{&points[2]}, 5)
+
+			// Removing final root node should return tree to empty state
+			removed, _, err = tree.Remove(&points[2])
+			if err != nil {
+				t.Fatalf("Expected removal to succeed but got error: %v", err)
+			}
+			if expected, actual := &points[2], removed; expected != actual {
+				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
+			}
+			store.expectSavedTree(t, 5, nil, 5)
+
+			// Re-inserting a node should make it a new root
+			err = tree.Insert(&points[1])
+			if err != nil {
+				t.Fatalf("Expected insertion to succeed but got error: %v", err)
+			}
+			store.expectSavedTree(t, 6, []*Point{&points[1]}, math.MaxInt32)
+		})
+
+		t.Run("allows all remaining nodes to be findable after removal", func(t *testing.T) {
+			//rand.Seed(123)
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			points := randomPoints(10)
+			_, _ = insertPoints(points, tree)
+
+			var pointsToRemove []*Point
+			for i := range points {
+				pointsToRemove = append(pointsToRemove, &points[i])
+			}
+			rand.Shuffle(len(pointsToRemove), func(i, j int) {
+				pointsToRemove[i], pointsToRemove[j] = pointsToRemove[j], pointsToRemove[i]
+			})
+
+			for i, p := range pointsToRemove {
+				removed, _, _ := tree.Remove(p)
+
+				if expected, actual := p, removed; expected != actual {
+					t.Errorf("Expected %v to have been removed but got %v", expected, actual)
+				}
+
+				nodeCount := traverseTree(tree, tree.store.(*inMemoryStore[*Point]), false)
+				if expected, actual := len(points)-i-1, nodeCount; expected != actual {
+					t.Fatalf("Expected %d nodes remaining after %d removals but found %d", expected, i+1, actual)
+				}
+
+				// Removed node should no longer be findable
+				results, _ := tree.FindNearest(p, 1, 0)
+				expectSameResults(t, *p, results, nil)
+
+				// All other nodes should still be findable
+				for j := i + 1; j < len(pointsToRemove); j++ {
+					results, _ = tree.FindNearest(pointsToRemove[j], 1, 0)
+					expectSameResults(t, *pointsToRemove[j], results, []ItemWithDistance[*Point]{{pointsToRemove[j], 0}})
+				}
+			}
+		})
+	})
+
+	t.Run("RemoveContext()", func(t *testing.T) {
+
+		t.Run("removes the item while preserving its children", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			points := randomPoints(20)
+			_, _ = insertPoints(points, tree)
+
+			removed, _, err := tree.RemoveContext(context.Background(), &points[0])
+
+			if err != nil {
+				t.Fatalf("Expected removal to succeed but got error: %v", err)
+			}
+			if expected, actual := &points[0], removed; expected != actual {
+				t.Errorf("Expected %v to have been removed but got %v", expected, actual)
+			}
+			if expected, actual := len(points)-1, traverseTree(tree, tree.store.(*inMemoryStore[*Point]), false); expected != actual {
+				t.Errorf("Expected %d nodes remaining after removal but found %d", expected, actual)
+			}
+		})
+
+		t.Run("does not remove the item when cancelled", func(t *testing.T) {
+			tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+
+			points := randomPoints(20)
+			_, _ = insertPoints(points, tree)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			removed, _, err := tree.RemoveContext(ctx, &points[0])
+
+			if expected, actual := context.Canceled, err; expected != actual {
+				t.Errorf("Expected error %v but got %v", expected, actual)
+			}
+			if removed != nil {
+				t.Errorf("Expected nothing to have been removed but got %v", removed)
+			}
+			if expected, actual := len(points), traverseTree(tree, tree.store.(*inMemoryStore[*Point]), false); expected != actual {
+				t.Errorf("Expected %d nodes in tree but found %d", expected, actual)
+			}
+		})
+	})
+
+	t.Run("supports value items", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, func(a, b int) float64 {
+			return math.Abs(float64(a - b))
+		})
+
+		for _, item := range []int{1, 5, 9, 20} {
+			_ = tree.Insert(item)
+		}
+
+		results, err := tree.FindNearest(8, 2, math.MaxFloat64)
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if expected, actual := []ItemWithDistance[int]{{9, 1}, {5, 3}}, results; len(actual) != 2 || expected[0] != actual[0] || expected[1] != actual[1] {
+			t.Errorf("Expected results %v but got %v", expected, actual)
+		}
+	})
+
+	t.Run("with randomly populated tree", func(t *testing.T) {
+		distanceCalls := 0
+		store := NewInMemoryStore(distanceBetweenPoints)
+		tree, _ := NewTreeWithStore[*Point](store, 2, 1000.0, distanceBetweenPointsWithCounter(&distanceCalls))
+
+		points := randomPoints(1000)
+
+		timeTaken, err := insertPoints(points, tree)
+		if err != nil {
+			t.Fatalf("Error inserting point: %v", err)
+		}
+		fmt.Printf("Building tree took %d distance calls, %dms\n", distanceCalls, timeTaken/time.Millisecond)
+
+		nodeCount := traverseTree(tree, store, false)
+		fmt.Printf("Found %d nodes in tree\n", nodeCount)
+		if expected := len(points); nodeCount != expected {
+			t.Fatalf("Expected %d nodes in tree but found %d", expected, nodeCount)
+		}
+
+		t.Run("FindNearest()", func(t *testing.T) {
+
+			t.Run("can find all nodes individually", func(t *testing.T) {
+				for i := range points {
+					results, _ := tree.FindNearest(&points[i], 1, 0)
+					expectSameResults(t, points[i], results, []ItemWithDistance[*Point]{{&points[i], 0}})
+				}
+			})
+
+			t.Run("returns correct results for nearest neighbour query", func(t *testing.T) {
+				for i := 0; i < 100; i++ {
+					fmt.Println()
+					compareWithLinearSearch(tree, points, 1, math.MaxFloat64, &distanceCalls, t)
+				}
+			})
+
+			t.Run("returns correct results for k-nearest neighbour query", func(t *testing.T) {
+				for i := 0; i < 100; i++ {
+					fmt.Println()
+					compareWithLinearSearch(tree, points, 8, math.MaxFloat64, &distanceCalls, t)
+				}
+			})
+
+			t.Run("returns correct results for bounded distance query", func(t *testing.T) {
+				for i := 0; i < 100; i++ {
+					fmt.Println()
+					compareWithLinearSearch(tree, points, 1, 25, &distanceCalls, t)
+				}
+			})
+
+			t.Run("returns correct results for k-nearest bounded distance query", func(t *testing.T) {
+				for i := 0; i < 100; i++ {
+					fmt.Println()
+					compareWithLinearSearch(tree, points, 8, 50, &distanceCalls, t)
+				}
+			})
+		})
+
+		t.Run("FindNearestApprox()", func(t *testing.T) {
+
+			t.Run("returns exact results when epsilon is zero", func(t *testing.T) {
+				for i := 0; i < 100; i++ {
+					query := randomPoint()
+
+					results, err := tree.FindNearestApprox(&query, 8, math.MaxFloat64, 0)
+					if err != nil {
+						t.Fatalf("Error querying tree: %v", err)
+					}
+
+					expectedResults, _ := tree.FindNearest(&query, 8, math.MaxFloat64)
+					expectSameResults(t, query, results, expectedResults)
+				}
+			})
+
+			t.Run("returns results within the approximation factor", func(t *testing.T) {
+				const epsilon = 0.5
+
+				for i := 0; i < 100; i++ {
+					query := randomPoint()
+
+					results, err := tree.FindNearestApprox(&query, 8, math.MaxFloat64, epsilon)
+					if err != nil {
+						t.Fatalf("Error querying tree: %v", err)
+					}
+
+					expectedResults, _ := linearSearch(&query, points, 8, math.MaxFloat64)
+
+					if expected, actual := len(expectedResults), len(results); expected != actual {
+						t.Fatalf("Expected %d results for %v but got %d instead", expected, query, actual)
+					}
+					for j := range results {
+						if limit, actual := expectedResults[j].Distance*(1+epsilon), results[j].Distance; actual > limit {
+							t.Errorf("Expected distance of result %d to be within %g but was %g", j, limit, actual)
+						}
+					}
+				}
+			})
+
+			t.Run("returns an error when epsilon is negative", func(t *testing.T) {
+				query := randomPoint()
+
+				_, err := tree.FindNearestApprox(&query, 8, math.MaxFloat64, -0.5)
+
+				if err == nil {
+					t.Errorf("Expected an error but got none")
+				}
+			})
+		})
+
+		t.Run("FindNearestBatch()", func(t *testing.T) {
+
+			t.Run("returns the same results as individual queries", func(t *testing.T) {
+				queryPoints := randomPoints(100)
+
+				queries := make([]*Point, len(queryPoints))
+				for i := range queryPoints {
+					queries[i] = &queryPoints[i]
+				}
+
+				results, err := tree.FindNearestBatch(queries, 8, 100)
+				if err != nil {
+					t.Fatalf("Error querying tree: %v", err)
+				}
+
+				if expected, actual := len(queries), len(results); expected != actual {
+					t.Fatalf("Expected %d result sets but got %d", expected, actual)
+				}
+				for i := range queryPoints {
+					expectedResults, _ := tree.FindNearest(&queryPoints[i], 8, 100)
+					expectSameResults(t, queryPoints[i], results[i], expectedResults)
+				}
+			})
+
+			t.Run("loads the children for each level in a single call", func(t *testing.T) {
+				queryPoints := randomPoints(100)
+
+				queries := make([]*Point, len(queryPoints))
+				for i := range queryPoints {
+					queries[i] = &queryPoints[i]
+				}
+
+				tracer := tree.NewTracer()
+				_, err := tracer.FindNearestBatch(queries, 8, 100)
+				if err != nil {
+					t.Fatalf("Error querying tree: %v", err)
+				}
+
+				if expected, actual := tracer.MaxLevelsTraversed+1, tracer.LoadChildrenCount; actual > expected {
+					t.Errorf("Expected at most %d LoadChildren calls but got %d", expected, actual)
+				}
+			})
+		})
+
+		t.Run("FindNearestMatching()", func(t *testing.T) {
+
+			t.Run("returns correct results for filtered k-nearest neighbour query", func(t *testing.T) {
+				matches := func(item *Point) bool {
+					return item[0] < 250
+				}
+
+				var matchingPoints []Point
+				for i := range points {
+					if matches(&points[i]) {
+						matchingPoints = append(matchingPoints, points[i])
+					}
+				}
+
+				for i := 0; i < 100; i++ {
+					query := randomPoint()
+
+					results, err := tree.FindNearestMatching(&query, 8, math.MaxFloat64, matches)
+					if err != nil {
+						t.Fatalf("Error querying tree: %v", err)
+					}
+
+					expectedResults, _ := linearSearch(&query, matchingPoints, 8, math.MaxFloat64)
+
+					if expected, actual := len(expectedResults), len(results); expected != actual {
+						t.Fatalf("Expected %d results for %v but got %d instead", expected, query, actual)
+					}
+					for j := range results {
+						if !matches(results[j].Item) {
+							t.Errorf("Expected result %d to match but got %v", j, results[j].Item)
+						}
+						if expected, actual := expectedResults[j].Distance, results[j].Distance; expected != actual {
+							t.Errorf("Expected distance of result %d to be %g but was %g", j, expected, actual)
+						}
+					}
+				}
+			})
+		})
+
+		t.Run("CountWithin()", func(t *testing.T) {
+
+			t.Run("returns correct results for radius count", func(t *testing.T) {
+				for _, radius := range []float64{0, 50, 250, 1000, math.MaxFloat64} {
+					query := randomPoint()
+
+					count, err := tree.CountWithin(&query, radius)
+					if err != nil {
+						t.Fatalf("Error querying tree: %v", err)
+					}
+
+					expectedResults, _ := tree.FindWithin(&query, radius)
+					if expected, actual := len(expectedResults), count; expected != actual {
+						t.Errorf("Expected count of %d within %g of %v but got %d", expected, radius, query, actual)
+					}
+				}
+			})
+
+			t.Run("returns correct results when the store cannot report subtree sizes", func(t *testing.T) {
+				plainTree, _ := NewTreeWithStore[*Point](struct{ Store[*Point] }{store}, 2, 1000.0, distanceBetweenPoints)
+
+				for _, radius := range []float64{0, 50, 250, 1000, math.MaxFloat64} {
+					query := randomPoint()
+
+					count, err := plainTree.CountWithin(&query, radius)
+					if err != nil {
+						t.Fatalf("Error querying tree: %v", err)
+					}
+
+					expectedResults, _ := tree.FindWithin(&query, radius)
+					if expected, actual := len(expectedResults), count; expected != actual {
+						t.Errorf("Expected count of %d within %g of %v but got %d", expected, radius, query, actual)
+					}
+				}
+			})
+
+			t.Run("counts covered subtrees without computing their distances", func(t *testing.T) {
+				query := randomPoint()
+
+				distanceCalls = 0
+				count, _ := tree.CountWithin(&query, math.MaxFloat64)
+
+				if expected, actual := len(points), count; expected != actual {
+					t.Errorf("Expected count of %d but got %d", expected, actual)
+				}
+				if distanceCalls >= len(points) {
+					t.Errorf("Expected fewer than %d distance calculations but got %d", len(points), distanceCalls)
+				}
+			})
+		})
+
+		t.Run("FindFurthest()", func(t *testing.T) {
+
+			t.Run("returns correct results for k-furthest query", func(t *testing.T) {
+				for i := 0; i < 20; i++ {
+					query := randomPoint()
+
+					results, err := tree.FindFurthest(&query, 8, 0)
+					if err != nil {
+						t.Fatalf("Error querying tree: %v", err)
+					}
+
+					allResults, _ := linearSearch(&query, points, len(points), math.MaxFloat64)
+					var expectedResults []ItemWithDistance[*Point]
+					for j := len(allResults) - 1; j >= len(allResults)-8; j-- {
+						expectedResults = append(expectedResults, allResults[j])
+					}
+
+					expectSameResults(t, query, results, expectedResults)
+				}
+			})
+		})
+
+		t.Run("FindReverseNearest()", func(t *testing.T) {
+
+			t.Run("returns correct results for reverse k-nearest neighbour query", func(t *testing.T) {
+				const maxNeighbours = 3
+
+				neighbourDistances := make([]float64, len(points))
+				for i := range points {
+					neighbours, _ := linearSearch(&points[i], points, maxNeighbours+1, math.MaxFloat64)
+					neighbourDistances[i] = neighbours[maxNeighbours].Distance
+				}
+
+				for i := 0; i < 20; i++ {
+					query := randomPoint()
+
+					results, err := tree.FindReverseNearest(&query, maxNeighbours)
+					if err != nil {
+						t.Fatalf("Error querying tree: %v", err)
+					}
+
+					var expectedResults []ItemWithDistance[*Point]
+					for j := range points {
+						if dist := distanceBetweenPoints(&points[j], &query); dist <= neighbourDistances[j] {
+							expectedResults = append(expectedResults, ItemWithDistance[*Point]{&points[j], dist})
+						}
+					}
+					sort.SliceStable(expectedResults, func(i, j int) bool {
+						return expectedResults[i].Distance < expectedResults[j].Distance
+					})
+
+					expectSameResults(t, query, results, expectedResults)
+				}
+			})
+		})
+
+		t.Run("FindWithin()", func(t *testing.T) {
+
+			t.Run("returns correct results for radius query", func(t *testing.T) {
+				for i := 0; i < 100; i++ {
+					query := randomPoint()
+
+					results, err := tree.FindWithin(&query, 100)
+					if err != nil {
+						t.Fatalf("Error querying tree: %v", err)
+					}
+
+					expectedResults, _ := linearSearch(&query, points, len(points), 100)
+					expectSameResults(t, query, results, expectedResults)
+				}
+			})
+		})
+
+		t.Run("KernelDensity()", func(t *testing.T) {
+
+			kernels := map[string]Kernel{
+				"Epanechnikov": EpanechnikovKernel,
+				"Gaussian":     GaussianKernel,
+				"uniform":      UniformKernel,
+			}
+
+			linearDensity := func(query *Point, kernel Kernel, bandwidth float64) (density float64) {
+				for i := range points {
+					density += kernel(distanceBetweenPoints(query, &points[i]), bandwidth)
+				}
+				return
+			}
+
+			t.Run("returns exact results when tolerance is zero", func(t *testing.T) {
+				for name, kernel := range kernels {
+					for _, bandwidth := range []float64{10, 100, 1000} {
+						query := randomPoint()
+
+						density, err := tree.KernelDensity(&query, kernel, bandwidth, 0)
+						if err != nil {
+							t.Fatalf("Error querying tree: %v", err)
+						}
+
+						if expected, actual := linearDensity(&query, kernel, bandwidth), density; math.Abs(expected-actual) > 1e-9 {
+							t.Errorf("Expected %s density of %g at bandwidth %g but got %g", name, expected, bandwidth, actual)
+						}
+					}
+				}
+			})
+
+			t.Run("returns results within the tolerance", func(t *testing.T) {
+				const tolerance = 5.0
+
+				for name, kernel := range kernels {
+					for _, bandwidth := range []float64{10, 100, 1000} {
+						query := randomPoint()
+
+						density, err := tree.KernelDensity(&query, kernel, bandwidth, tolerance)
+						if err != nil {
+							t.Fatalf("Error querying tree: %v", err)
+						}
+
+						if expected, actual := linearDensity(&query, kernel, bandwidth), density; math.Abs(expected-actual) > tolerance {
+							t.Errorf("Expected %s density within %g of %g at bandwidth %g but got %g", name, tolerance, expected, bandwidth, actual)
+						}
+					}
+				}
+			})
+
+			t.Run("estimates subtrees without computing their distances", func(t *testing.T) {
+				query := randomPoint()
+
+				distanceCalls = 0
+				_, _ = tree.KernelDensity(&query, GaussianKernel, 100, 20.0)
+
+				if distanceCalls >= len(points)/2 {
+					t.Errorf("Expected fewer than %d distance calculations but got %d", len(points)/2, distanceCalls)
+				}
+			})
+		})
+	})
+
+	t.Run("with items which cannot be compared", func(t *testing.T) {
+
+		t.Run("FindReverseNearest() counts duplicates as neighbours of each other", func(t *testing.T) {
+			tree, _ := newSliceTree([]float64{100}, []float64{10}, []float64{0}, []float64{0})
+
+			results, err := tree.FindReverseNearest([]float64{1}, 1)
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if expected, actual := 1, len(results); expected != actual {
+				t.Fatalf("Expected %d result but got %v", expected, results)
+			}
+			if expected, actual := 10.0, results[0].Item[0]; expected != actual {
+				t.Errorf("Expected result %g but got %g", expected, actual)
+			}
+		})
+
+		t.Run("Remove() removes only one of several duplicates", func(t *testing.T) {
+			tree, _ := newSliceTree([]float64{100}, []float64{10}, []float64{0}, []float64{0})
+
+			_, ok, err := tree.Remove([]float64{0})
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if !ok {
+				t.Fatalf("Expected an item to be removed")
+			}
+			results, _ := tree.FindWithin([]float64{0}, 1000)
+			if expected, actual := 3, len(results); expected != actual {
+				t.Errorf("Expected %d remaining items but got %v", expected, results)
+			}
+		})
+	})
+}
@@ -0,0 +1,44 @@
+package typed
+
+// unionFind tracks the partitioning of a set of indices into disjoint sets,
+// each of which is identified by one of its members.
+type unionFind struct {
+	parents []int
+}
+
+func newUnionFind(size int) *unionFind {
+	u := &unionFind{}
+	for i := 0; i < size; i++ {
+		u.add()
+	}
+	return u
+}
+
+// add adds a new index in a set of its own, returning the index.
+func (u *unionFind) add() int {
+	i := len(u.parents)
+	u.parents = append(u.parents, i)
+	return i
+}
+
+// find returns the member which identifies the set containing the index.
+func (u *unionFind) find(i int) int {
+	for u.parents[i] != i {
+		u.parents[i] = u.parents[u.parents[i]]
+		i = u.parents[i]
+	}
+	return i
+}
+
+// union merges the sets containing the two indices, such that the merged set
+// is identified in the same way as the set containing i. It returns false if
+// the indices were already in the same set.
+func (u *unionFind) union(i, j int) bool {
+	i, j = u.find(i), u.find(j)
+	if i == j {
+		return false
+	}
+
+	u.parents[j] = i
+	return true
+}
@@ -0,0 +1,54 @@
+package typed
+
+import "testing"
+
+func TestUnionFind(t *testing.T) {
+
+	t.Run("find() returns each index for unmerged sets", func(t *testing.T) {
+		u := newUnionFind(4)
+
+		for i := 0; i < 4; i++ {
+			if expected, actual := i, u.find(i); expected != actual {
+				t.Errorf("Expected set of %d to be identified by %d but was %d", i, expected, actual)
+			}
+		}
+	})
+
+	t.Run("union() merges sets into the set of the first index", func(t *testing.T) {
+		u := newUnionFind(4)
+
+		if !u.union(0, 1) || !u.union(2, 3) || !u.union(1, 3) {
+			t.Fatalf("Expected disjoint sets to be merged")
+		}
+
+		for i := 0; i < 4; i++ {
+			if expected, actual := 0, u.find(i); expected != actual {
+				t.Errorf("Expected set of %d to be identified by %d but was %d", i, expected, actual)
+			}
+		}
+	})
+
+	t.Run("union() reports when indices are already in the same set", func(t *testing.T) {
+		u := newUnionFind(3)
+		_ = u.union(0, 1)
+		_ = u.union(1, 2)
+
+		if u.union(0, 2) {
+			t.Errorf("Expected indices in the same set not to be merged")
+		}
+	})
+
+	t.Run("add() adds indices in sets of their own", func(t *testing.T) {
+		u := newUnionFind(2)
+		_ = u.union(0, 1)
+
+		i := u.add()
+
+		if expected, actual := 2, i; expected != actual {
+			t.Fatalf("Expected index %d to be added but got %d", expected, actual)
+		}
+		if expected, actual := i, u.find(i); expected != actual {
+			t.Errorf("Expected set of %d to be identified by %d but was %d", i, expected, actual)
+		}
+	})
+}
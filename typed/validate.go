@@ -0,0 +1,272 @@
+package typed
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// ViolationKind identifies the invariant of a tree which a Violation breaks.
+type ViolationKind int
+
+const (
+	// CoveringViolation indicates an item which is further from its parent
+	// than is permitted at its level, or further from one of its ancestors
+	// than the levels of the ancestor’s children permit (as relied upon by
+	// searches which bound the extent of a subtree by its highest children).
+	// Items beneath it may be missing from search results.
+	CoveringViolation ViolationKind = iota
+
+	// SeparationViolation indicates an item which is no further than the
+	// distance for its level from another item at the same or a higher level,
+	// other than a duplicate at zero distance. This does not affect search
+	// results, only the efficiency of searches. The tree does not strictly
+	// enforce separation when items are inserted, so this may result from
+	// ordinary insertions as well as from removals.
+	SeparationViolation
+
+	// LevelViolation indicates an item which is not at a lower level than its
+	// parent. Items beneath it may be missing from search results.
+	LevelViolation
+
+	// DuplicateViolation indicates an item which appears more than once in the
+	// tree, either beneath different parents or beneath itself.
+	DuplicateViolation
+
+	// ReachabilityViolation indicates a root item which is not at the tree’s
+	// root level, and is therefore never visited by tree operations.
+	ReachabilityViolation
+)
+
+func (k ViolationKind) String() string {
+	switch k {
+	case CoveringViolation:
+		return "covering"
+	case SeparationViolation:
+		return "separation"
+	case LevelViolation:
+		return "level"
+	case DuplicateViolation:
+		return "duplicate"
+	case ReachabilityViolation:
+		return "reachability"
+	}
+
+	return fmt.Sprintf("ViolationKind(%d)", int(k))
+}
+
+// Violation describes an item in a tree which breaks one of the tree’s
+// invariants.
+//
+// Item is the offending item, as stored beneath Parent at Level. Parent is nil
+// for root items, in which case ParentLevel is not meaningful.
+//
+// For covering and separation violations, Other is the item which the
+// offending item is too far from or too close to (which may be Parent), and
+// Distance is the distance between them. For other kinds of violation, Other is
+// the zero T.
+type Violation[T any] struct {
+	Kind        ViolationKind
+	Item        T
+	Level       int
+	Parent      *T
+	ParentLevel int
+	Other       T
+	Distance    float64
+}
+
+// Validate checks the structure of the tree, as loaded from its Store, and
+// returns any violations of its invariants which are found.
+//
+// Every item reachable from the roots of the tree is visited, loading the
+// children for each level of the tree with a single call to the Store. Items
+// which are beneath a level or duplicate violation are not visited, so that
+// traversal terminates even when the Store describes a cycle.
+//
+// Separation is checked between every pair of visited items which are present
+// at each level, including items stored at higher levels. Rather than
+// comparing every pair, the distances between parents and their children are
+// used to rule out subtrees which are too far away to contain a violation. The
+// same distances are used to rule out subtrees which are close enough to their
+// ancestors, before checking the remaining items against them.
+//
+// Items which appear more than once in the tree can only be detected if they
+// are comparable.
+//
+// The entire structure of the tree is loaded into memory for the duration of
+// the operation. Multiple calls to Validate and Insert are safe to make
+// concurrently, but concurrent insertions may be reported as violations.
+func (t *Tree[T]) Validate() (violations []Violation[T], err error) {
+	tracer := t.NewTracer()
+
+	roots, err := tracer.loadChildren(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, level := range roots[0].levels() {
+		if level == t.rootLevel {
+			continue
+		}
+		for _, item := range roots[0].itemsAt(level) {
+			violations = append(violations, Violation[T]{Kind: ReachabilityViolation, Item: item, Level: level})
+		}
+	}
+
+	var items, rootItems []*validatedItem[T]
+	var zero T
+	seen := make(map[interface{}]bool)
+
+	err = traverseBreadthFirst(t, tracer, true, func(item T, parent *validatedItem[T], level int) (*validatedItem[T], error) {
+		v := &validatedItem[T]{item: item, level: level, parent: parent, index: len(items)}
+
+		if parent != nil {
+			if level >= parent.level {
+				violations = append(violations, v.violation(LevelViolation, zero, 0))
+				return nil, SkipChildren
+			}
+
+			v.parentDistance = t.distanceBetween(item, parent.item)
+			if v.parentDistance > t.distanceForLevel(level+1) {
+				violations = append(violations, v.violation(CoveringViolation, parent.item, v.parentDistance))
+			}
+		}
+
+		if key := interface{}(item); key != nil && reflect.TypeOf(key).Comparable() {
+			if seen[key] {
+				violations = append(violations, v.violation(DuplicateViolation, zero, 0))
+				return nil, SkipChildren
+			}
+			seen[key] = true
+		}
+
+		if parent != nil {
+			parent.children = append(parent.children, v)
+		} else {
+			rootItems = append(rootItems, v)
+		}
+		items = append(items, v)
+		return v, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Items are visited before their descendants, so visiting them in reverse
+	// determines the radius of each subtree before that of its parent
+	for i := len(items) - 1; i >= 0; i-- {
+		if parent := items[i].parent; parent != nil {
+			parent.radius = math.Max(parent.radius, items[i].parentDistance+items[i].radius)
+		}
+	}
+
+	for _, v := range items {
+		violations = t.appendNestingViolations(violations, v)
+		violations = t.appendSeparationViolations(violations, v, rootItems)
+	}
+
+	return violations, nil
+}
+
+// appendNestingViolations appends a covering violation for each descendant of
+// the specified item which is further from it than coverDistanceForNode allows
+// for the item. Children themselves are not checked, as they are already
+// checked against the distance for their level.
+func (t *Tree[T]) appendNestingViolations(violations []Violation[T], v *validatedItem[T]) []Violation[T] {
+	if len(v.children) == 0 {
+		return violations
+	}
+
+	maxChildLevel := v.children[0].level
+	for _, child := range v.children[1:] {
+		if child.level > maxChildLevel {
+			maxChildLevel = child.level
+		}
+	}
+
+	coverDistance := t.distanceForLevel(maxChildLevel + 1)
+	if v.radius <= coverDistance {
+		return violations
+	}
+
+	candidates := v.children
+	for len(candidates) > 0 {
+		var nextCandidates []*validatedItem[T]
+
+		for _, c := range candidates {
+			distance := c.parentDistance
+			if c.parent != v {
+				distance = t.distanceBetween(v.item, c.item)
+				if distance > coverDistance {
+					violations = append(violations, c.violation(CoveringViolation, v.item, distance))
+				}
+			}
+
+			if distance+c.radius > coverDistance {
+				nextCandidates = append(nextCandidates, c.children...)
+			}
+		}
+
+		candidates = nextCandidates
+	}
+
+	return violations
+}
+
+// appendSeparationViolations appends a violation for each item amongst the
+// candidates and their descendants which is present at the level of the
+// specified item and within the distance for that level. Each pair of items is
+// reported once, by whichever item is at the lower level or visited later.
+func (t *Tree[T]) appendSeparationViolations(violations []Violation[T], v *validatedItem[T], candidates []*validatedItem[T]) []Violation[T] {
+	distThreshold := t.distanceForLevel(v.level)
+
+	for len(candidates) > 0 {
+		var nextCandidates []*validatedItem[T]
+
+		for _, c := range candidates {
+			if c == v {
+				continue
+			}
+
+			distance := t.distanceBetween(v.item, c.item)
+			if distance != 0 && distance <= distThreshold && (c.level > v.level || c.index < v.index) {
+				violations = append(violations, v.violation(SeparationViolation, c.item, distance))
+			}
+
+			if distance-c.radius > distThreshold {
+				continue
+			}
+			for _, child := range c.children {
+				if child.level >= v.level {
+					nextCandidates = append(nextCandidates, child)
+				}
+			}
+		}
+
+		candidates = nextCandidates
+	}
+
+	return violations
+}
+
+// validatedItem represents an item which has been visited by Validate, along
+// with the items visited beneath it and the greatest distance of any of them
+// from the item, derived from the distances between parents and children.
+type validatedItem[T any] struct {
+	item           T
+	level          int
+	index          int
+	parent         *validatedItem[T]
+	parentDistance float64
+	children       []*validatedItem[T]
+	radius         float64
+}
+
+func (v *validatedItem[T]) violation(kind ViolationKind, other T, distance float64) Violation[T] {
+	violation := Violation[T]{Kind: kind, Item: v.item, Level: v.level, Other: other, Distance: distance}
+	if v.parent != nil {
+		violation.Parent = &v.parent.item
+		violation.ParentLevel = v.parent.level
+	}
+	return violation
+}
@@ -0,0 +1,213 @@
+package typed
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTreeValidate(t *testing.T) {
+
+	parentOf := func(v Violation[*Point]) *Point {
+		if v.Parent == nil {
+			return nil
+		}
+		return *v.Parent
+	}
+
+	expectViolations := func(t *testing.T, expectedViolations, actualViolations []Violation[*Point]) {
+		t.Helper()
+
+		if expected, actual := len(expectedViolations), len(actualViolations); expected != actual {
+			t.Fatalf("Expected %d violations but got %d: %v", expected, actual, actualViolations)
+		}
+		for i := range expectedViolations {
+			expected, actual := expectedViolations[i], actualViolations[i]
+			if expectedParent, actualParent := parentOf(expected), parentOf(actual); expectedParent != actualParent {
+				t.Errorf("Expected violation %d to have parent %v but got %v", i, expectedParent, actualParent)
+			}
+
+			expected.Parent, actual.Parent = nil, nil
+			if expected != actual {
+				t.Errorf("Expected violation %d to be %+v but got %+v", i, expected, actual)
+			}
+		}
+	}
+
+	withoutSeparationViolations := func(violations []Violation[*Point]) (result []Violation[*Point]) {
+		for _, v := range violations {
+			if v.Kind != SeparationViolation {
+				result = append(result, v)
+			}
+		}
+		return result
+	}
+
+	treeWithStore := func() (*Tree[*Point], *inMemoryStore[*Point]) {
+		store := NewInMemoryStore(distanceBetweenPoints)
+		tree, _ := NewTreeWithStore[*Point](store, 2, 8, distanceBetweenPoints)
+		return tree, store
+	}
+
+	t.Run("reports only separation violations for trees built by insertion", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		_, _ = insertPoints(randomPoints(1000), tree)
+
+		violations, err := tree.Validate()
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		expectViolations(t, nil, withoutSeparationViolations(violations))
+	})
+
+	t.Run("reports only separation violations for trees built from items", func(t *testing.T) {
+		points := randomPoints(1000)
+		items := make([]*Point, len(points))
+		for i := range points {
+			items[i] = &points[i]
+		}
+		tree, _ := NewTreeFromItems[*Point](NewInMemoryStore(distanceBetweenPoints), 2, items, distanceBetweenPoints)
+
+		violations, err := tree.Validate()
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		expectViolations(t, nil, withoutSeparationViolations(violations))
+	})
+
+	t.Run("reports children beyond the covering distance of their parents", func(t *testing.T) {
+		tree, store := treeWithStore()
+		root, child := &Point{0, 0, 0}, &Point{3, 0, 0}
+		_ = store.AddItem(root, nil, 3)
+		_ = store.AddItem(child, &root, 0)
+
+		violations, _ := tree.Validate()
+
+		expectViolations(t, []Violation[*Point]{{CoveringViolation, child, 0, &root, 3, root, 3}}, violations)
+	})
+
+	t.Run("reports descendants beyond the covering distance of their ancestors", func(t *testing.T) {
+		tree, store := treeWithStore()
+		root, child, grandchild := &Point{0, 0, 0}, &Point{4, 0, 0}, &Point{6, 0, 0}
+		_ = store.AddItem(root, nil, 3)
+		_ = store.AddItem(child, &root, 1)
+		_ = store.AddItem(grandchild, &child, 0)
+
+		violations, _ := tree.Validate()
+
+		expectViolations(t, []Violation[*Point]{{CoveringViolation, grandchild, 0, &child, 1, root, 6}}, violations)
+	})
+
+	t.Run("reports children within the separation distance of their parents and siblings", func(t *testing.T) {
+		tree, store := treeWithStore()
+		root, child1, child2, duplicate := &Point{0, 0, 0}, &Point{3, 0, 0}, &Point{4, 0, 0}, &Point{4, 0, 0}
+		_ = store.AddItem(root, nil, 3)
+		_ = store.AddItem(child1, &root, 2)
+		_ = store.AddItem(child2, &root, 2)
+		_ = store.AddItem(duplicate, &root, 2)
+
+		violations, _ := tree.Validate()
+
+		expectViolations(t, []Violation[*Point]{
+			{SeparationViolation, child1, 2, &root, 3, root, 3},
+			{SeparationViolation, child2, 2, &root, 3, root, 4},
+			{SeparationViolation, child2, 2, &root, 3, child1, 1},
+			{SeparationViolation, duplicate, 2, &root, 3, root, 4},
+			{SeparationViolation, duplicate, 2, &root, 3, child1, 1},
+		}, violations)
+	})
+
+	t.Run("reports items within the separation distance of items beneath other parents", func(t *testing.T) {
+		tree, store := treeWithStore()
+		root, child1, child2 := &Point{0, 0, 0}, &Point{5, 0, 0}, &Point{0, 5, 0}
+		grandchild1, grandchild2 := &Point{3, 2, 0}, &Point{2, 3, 0}
+		_ = store.AddItem(root, nil, 3)
+		_ = store.AddItem(child1, &root, 2)
+		_ = store.AddItem(child2, &root, 2)
+		_ = store.AddItem(grandchild1, &child1, 1)
+		_ = store.AddItem(grandchild2, &child2, 1)
+
+		violations, _ := tree.Validate()
+
+		expectViolations(t, []Violation[*Point]{
+			{SeparationViolation, grandchild2, 1, &child2, 2, grandchild1, distanceBetweenPoints(grandchild1, grandchild2)},
+		}, violations)
+	})
+
+	t.Run("reports items within the separation distance of items at higher levels", func(t *testing.T) {
+		tree, store := treeWithStore()
+		root, child1, child2, grandchild := &Point{0, 0, 0}, &Point{4.5, 0, 0}, &Point{3, 2, 0}, &Point{3.5, 1.2, 0}
+		_ = store.AddItem(root, nil, 3)
+		_ = store.AddItem(child1, &root, 2)
+		_ = store.AddItem(child2, &root, 1)
+		_ = store.AddItem(grandchild, &child1, 0)
+
+		violations, _ := tree.Validate()
+
+		expectViolations(t, []Violation[*Point]{
+			{SeparationViolation, grandchild, 0, &child1, 2, child2, distanceBetweenPoints(grandchild, child2)},
+		}, violations)
+	})
+
+	t.Run("reports roots within the separation distance of each other", func(t *testing.T) {
+		tree, store := treeWithStore()
+		root1, root2 := &Point{0, 0, 0}, &Point{5, 0, 0}
+		_ = store.AddItem(root1, nil, 3)
+		_ = store.AddItem(root2, nil, 3)
+
+		violations, _ := tree.Validate()
+
+		expectViolations(t, []Violation[*Point]{{SeparationViolation, root2, 3, nil, 0, root1, 5}}, violations)
+	})
+
+	t.Run("reports children which are not below the level of their parents", func(t *testing.T) {
+		tree, store := treeWithStore()
+		root, child, grandchild := &Point{0, 0, 0}, &Point{1, 0, 0}, &Point{1, 1, 0}
+		_ = store.AddItem(root, nil, 3)
+		_ = store.AddItem(child, &root, 3)
+		_ = store.AddItem(grandchild, &child, 20)
+
+		violations, _ := tree.Validate()
+
+		expectViolations(t, []Violation[*Point]{{Kind: LevelViolation, Item: child, Level: 3, Parent: &root, ParentLevel: 3}}, violations)
+	})
+
+	t.Run("reports items which appear more than once", func(t *testing.T) {
+		tree, store := treeWithStore()
+		root, child1, child2 := &Point{0, 0, 0}, &Point{6, 0, 0}, &Point{0, 6, 0}
+		_ = store.AddItem(root, nil, 3)
+		_ = store.AddItem(child1, &root, 2)
+		_ = store.AddItem(child2, &root, 2)
+		_ = store.UpdateItem(root, &child1, 1)
+
+		violations, _ := tree.Validate()
+
+		expectViolations(t, []Violation[*Point]{
+			{Kind: CoveringViolation, Item: root, Level: 1, Parent: &child1, ParentLevel: 2, Other: child1, Distance: 6},
+			{Kind: DuplicateViolation, Item: root, Level: 1, Parent: &child1, ParentLevel: 2},
+		}, violations)
+	})
+
+	t.Run("reports roots which are not at the root level", func(t *testing.T) {
+		tree, store := treeWithStore()
+		root, misplaced := &Point{0, 0, 0}, &Point{100, 0, 0}
+		_ = store.AddItem(root, nil, 3)
+		_ = store.AddItem(misplaced, nil, 7)
+
+		violations, _ := tree.Validate()
+
+		expectViolations(t, []Violation[*Point]{{Kind: ReachabilityViolation, Item: misplaced, Level: 7}}, violations)
+	})
+
+	t.Run("returns errors from the store", func(t *testing.T) {
+		storeErr := errors.New("store failure")
+		tree, _ := NewTreeWithStore[*Point](&failingStore{err: storeErr}, 2, 8, distanceBetweenPoints)
+
+		_, err := tree.Validate()
+
+		if expected, actual := storeErr, err; expected != actual {
+			t.Errorf("Expected error %v but got %v", expected, actual)
+		}
+	})
+}
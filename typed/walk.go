@@ -0,0 +1,163 @@
+package typed
+
+import "errors"
+
+// SkipChildren may be returned by a WalkFunc to indicate that the children of
+// the visited item should not be walked. It is not returned as an error by
+// Walk.
+var SkipChildren = errors.New("skip children")
+
+// StopWalk may be returned by a WalkFunc to indicate that no further items
+// should be walked. It is not returned as an error by Walk.
+var StopWalk = errors.New("stop walk")
+
+// WalkFunc represents a function which is called for each item visited by
+// Walk, along with its parent (which is nil for root items) and the level at
+// which it is stored.
+type WalkFunc[T any] func(item T, parent *T, level int) error
+
+// WalkOrder determines the order in which Walk visits the items of a tree.
+type WalkOrder int
+
+const (
+	// DepthFirst visits each item before its children, and its children (and
+	// their descendants) before its subsequent siblings.
+	DepthFirst WalkOrder = iota
+
+	// BreadthFirst visits all the roots of the tree, then all of their
+	// children, then all of their children’s children, and so on.
+	BreadthFirst
+)
+
+// WalkOptions specifies how WalkWithOptions traverses a tree.
+//
+// If BatchLoads is true, children are loaded from the Store for many items
+// with a single call, rather than one call per item. For a breadth-first walk,
+// the children of all the items at the same depth are loaded together. For a
+// depth-first walk, the children of all the siblings of an item are loaded
+// together before the item is visited. This is much more efficient when
+// loading children is expensive, at the cost of loading children which may go
+// unvisited if the walk is stopped or children are skipped.
+type WalkOptions struct {
+	Order      WalkOrder
+	BatchLoads bool
+}
+
+// Walk calls the visit function for every item in the tree, in depth-first
+// order, with its children being loaded from the Store one item at a time.
+//
+// Walking stops at the first error returned by the visit function, which is
+// then returned by Walk. However, if the visit function returns SkipChildren,
+// the children of the item are skipped, and if it returns StopWalk, walking
+// stops and Walk returns nil.
+//
+// Children are visited in order from their highest to lowest levels.
+//
+// Multiple calls to Walk and Insert are safe to make concurrently, though
+// items inserted during a walk may not be visited.
+func (t *Tree[T]) Walk(visit WalkFunc[T]) error {
+	return t.WalkWithOptions(WalkOptions{}, visit)
+}
+
+// WalkWithOptions calls the visit function for every item in the tree, as for
+// Walk, traversing the tree in the manner specified by the options.
+//
+// Multiple calls to WalkWithOptions and Insert are safe to make concurrently,
+// though items inserted during a walk may not be visited.
+func (t *Tree[T]) WalkWithOptions(options WalkOptions, visit WalkFunc[T]) error {
+	tracer := t.NewTracer()
+
+	var err error
+	switch options.Order {
+	case BreadthFirst:
+		err = traverseBreadthFirst(t, tracer, options.BatchLoads, func(item T, parent *T, level int) (*T, error) {
+			return &item, visit(item, parent, level)
+		})
+
+	default:
+		var roots []LevelsWithItems[T]
+		if roots, err = tracer.loadChildren(nil); err != nil {
+			return err
+		}
+
+		w := walk[T]{
+			batchLoads: options.BatchLoads,
+			visit:      visit,
+			tracer:     tracer,
+		}
+		err = w.depthFirst(walkItems(roots[0].itemsAt(t.rootLevel), nil, t.rootLevel))
+	}
+
+	if err == StopWalk {
+		return nil
+	}
+	return err
+}
+
+type walk[T any] struct {
+	batchLoads bool
+	visit      WalkFunc[T]
+	tracer     *Tracer[T]
+}
+
+func (w *walk[T]) depthFirst(siblings []traversalItem[T, *T]) error {
+	var children []LevelsWithItems[T]
+	if w.batchLoads {
+		var err error
+		if children, err = w.loadChildren(siblings); err != nil {
+			return err
+		}
+	}
+
+	for i, wi := range siblings {
+		err := w.visit(wi.item, wi.parent, wi.level)
+		if err == SkipChildren {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		var childrenOfItem LevelsWithItems[T]
+		if w.batchLoads {
+			childrenOfItem = children[i]
+		} else {
+			c, err := w.loadChildren(siblings[i : i+1])
+			if err != nil {
+				return err
+			}
+			childrenOfItem = c[0]
+		}
+
+		err = w.depthFirst(childWalkItems(childrenOfItem, &siblings[i].item))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *walk[T]) loadChildren(siblings []traversalItem[T, *T]) ([]LevelsWithItems[T], error) {
+	items := make([]T, len(siblings))
+	for i := range siblings {
+		items[i] = siblings[i].item
+	}
+
+	return loadChildrenForTraversal(w.tracer, items, true)
+}
+
+func childWalkItems[T any](children LevelsWithItems[T], parent *T) (items []traversalItem[T, *T]) {
+	for _, level := range children.levels() {
+		items = append(items, walkItems(children.itemsAt(level), parent, level)...)
+	}
+	return items
+}
+
+func walkItems[T any](items []T, parent *T, level int) []traversalItem[T, *T] {
+	walkItems := make([]traversalItem[T, *T], len(items))
+	for i, item := range items {
+		walkItems[i] = traversalItem[T, *T]{item, parent, level}
+	}
+	return walkItems
+}
@@ -0,0 +1,245 @@
+package typed
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type loadCallCountingStore struct {
+	*inMemoryStore[*Point]
+	loadCallCount int
+}
+
+func (s *loadCallCountingStore) LoadChildren(parents ...**Point) ([]LevelsWithItems[*Point], error) {
+	s.loadCallCount++
+	return s.inMemoryStore.LoadChildren(parents...)
+}
+
+func TestTreeWalk(t *testing.T) {
+
+	allOptions := []WalkOptions{
+		{DepthFirst, false},
+		{DepthFirst, true},
+		{BreadthFirst, false},
+		{BreadthFirst, true},
+	}
+
+	nameFor := func(options WalkOptions) string {
+		order := "depth-first"
+		if options.Order == BreadthFirst {
+			order = "breadth-first"
+		}
+		return fmt.Sprintf("%s (batched: %v)", order, options.BatchLoads)
+	}
+
+	treeWithPoints := func(count int) (*Tree[*Point], *loadCallCountingStore, []Point) {
+		store := &loadCallCountingStore{inMemoryStore: NewInMemoryStore(distanceBetweenPoints)}
+		tree, _ := NewTreeWithStore[*Point](store, 2, 1000.0, distanceBetweenPoints)
+
+		points := randomPoints(count)
+		_, _ = insertPoints(points, tree)
+
+		return tree, store, points
+	}
+
+	t.Run("Walk()", func(t *testing.T) {
+
+		t.Run("visits items depth-first", func(t *testing.T) {
+			tree, _, points := treeWithPoints(200)
+
+			var ancestors []*Point
+			visited := 0
+
+			err := tree.Walk(func(item *Point, parent **Point, level int) error {
+				for len(ancestors) > 0 && (parent == nil || ancestors[len(ancestors)-1] != *parent) {
+					ancestors = ancestors[:len(ancestors)-1]
+				}
+				if parent != nil && len(ancestors) == 0 {
+					t.Errorf("Expected %v to be visited within its parent's subtree but it was not", item)
+				}
+
+				ancestors = append(ancestors, item)
+				visited++
+				return nil
+			})
+
+			if err != nil {
+				t.Fatalf("Expected success but got error: %v", err)
+			}
+			if expected, actual := len(points), visited; expected != actual {
+				t.Errorf("Expected %d items to be visited but got %d", expected, actual)
+			}
+		})
+	})
+
+	t.Run("WalkWithOptions()", func(t *testing.T) {
+
+		t.Run("visits every item once with its parent and level", func(t *testing.T) {
+			tree, store, points := treeWithPoints(200)
+
+			for _, options := range allOptions {
+				visited := make(map[*Point]bool)
+
+				err := tree.WalkWithOptions(options, func(item *Point, parent **Point, level int) error {
+					if visited[item] {
+						t.Errorf("Expected %v to be visited once %s", item, nameFor(options))
+					}
+					visited[item] = true
+
+					if parent == nil && level != tree.rootLevel {
+						t.Errorf("Expected root %v to be at level %d %s but got %d", item, tree.rootLevel, nameFor(options), level)
+					}
+
+					found := false
+					for _, child := range store.items[keyForParent(parent)][level] {
+						if child == item {
+							found = true
+						}
+					}
+					if !found {
+						t.Errorf("Expected %v to be stored beneath %v at level %d %s", item, keyForParent(parent), level, nameFor(options))
+					}
+					return nil
+				})
+
+				if err != nil {
+					t.Fatalf("Expected success %s but got error: %v", nameFor(options), err)
+				}
+				if expected, actual := len(points), len(visited); expected != actual {
+					t.Errorf("Expected %d items to be visited %s but got %d", expected, nameFor(options), actual)
+				}
+			}
+		})
+
+		t.Run("visits items breadth-first", func(t *testing.T) {
+			tree, _, _ := treeWithPoints(200)
+
+			for _, options := range allOptions[2:] {
+				depths := make(map[*Point]int)
+				lastDepth := 0
+
+				err := tree.WalkWithOptions(options, func(item *Point, parent **Point, level int) error {
+					depth := 0
+					if parent != nil {
+						parentDepth, ok := depths[*parent]
+						if !ok {
+							t.Errorf("Expected parent of %v to be visited before it %s", item, nameFor(options))
+						}
+						depth = parentDepth + 1
+					}
+
+					if depth < lastDepth {
+						t.Errorf("Expected %v at depth %d to be visited after items at depth %d %s", item, depth, lastDepth, nameFor(options))
+					}
+
+					depths[item] = depth
+					lastDepth = depth
+					return nil
+				})
+
+				if err != nil {
+					t.Fatalf("Expected success %s but got error: %v", nameFor(options), err)
+				}
+			}
+		})
+
+		t.Run("loads children with fewer calls when batched", func(t *testing.T) {
+			tree, store, _ := treeWithPoints(200)
+
+			for _, order := range []WalkOrder{DepthFirst, BreadthFirst} {
+				store.loadCallCount = 0
+				_ = tree.WalkWithOptions(WalkOptions{order, false}, func(item *Point, parent **Point, level int) error { return nil })
+				unbatchedCalls := store.loadCallCount
+
+				store.loadCallCount = 0
+				_ = tree.WalkWithOptions(WalkOptions{order, true}, func(item *Point, parent **Point, level int) error { return nil })
+				batchedCalls := store.loadCallCount
+
+				if batchedCalls >= unbatchedCalls {
+					t.Errorf("Expected fewer than %d calls to load children when batched but got %d", unbatchedCalls, batchedCalls)
+				}
+			}
+		})
+
+		t.Run("skips the children of items when requested", func(t *testing.T) {
+			tree, store, _ := treeWithPoints(200)
+
+			for _, options := range allOptions {
+				visited := 0
+
+				err := tree.WalkWithOptions(options, func(item *Point, parent **Point, level int) error {
+					visited++
+					return SkipChildren
+				})
+
+				if err != nil {
+					t.Fatalf("Expected success %s but got error: %v", nameFor(options), err)
+				}
+				if expected, actual := len(store.items[nil][tree.rootLevel]), visited; expected != actual {
+					t.Errorf("Expected %d roots to be visited %s but got %d", expected, nameFor(options), actual)
+				}
+			}
+		})
+
+		t.Run("stops walking when requested", func(t *testing.T) {
+			tree, _, _ := treeWithPoints(200)
+
+			for _, options := range allOptions {
+				visited := 0
+
+				err := tree.WalkWithOptions(options, func(item *Point, parent **Point, level int) error {
+					visited++
+					if visited == 10 {
+						return StopWalk
+					}
+					return nil
+				})
+
+				if err != nil {
+					t.Fatalf("Expected success %s but got error: %v", nameFor(options), err)
+				}
+				if expected, actual := 10, visited; expected != actual {
+					t.Errorf("Expected %d items to be visited %s but got %d", expected, nameFor(options), actual)
+				}
+			}
+		})
+
+		t.Run("returns errors from the visit function", func(t *testing.T) {
+			tree, _, _ := treeWithPoints(200)
+			visitErr := errors.New("visit failure")
+
+			for _, options := range allOptions {
+				visited := 0
+
+				err := tree.WalkWithOptions(options, func(item *Point, parent **Point, level int) error {
+					visited++
+					if visited == 10 {
+						return visitErr
+					}
+					return nil
+				})
+
+				if expected, actual := visitErr, err; expected != actual {
+					t.Errorf("Expected error %v %s but got %v", expected, nameFor(options), actual)
+				}
+				if expected, actual := 10, visited; expected != actual {
+					t.Errorf("Expected %d items to be visited %s but got %d", expected, nameFor(options), actual)
+				}
+			}
+		})
+
+		t.Run("returns errors from the store", func(t *testing.T) {
+			storeErr := errors.New("store failure")
+			tree, _ := NewTreeWithStore[*Point](&failingStore{err: storeErr}, 2, 1000.0, distanceBetweenPoints)
+
+			for _, options := range allOptions {
+				err := tree.WalkWithOptions(options, func(item *Point, parent **Point, level int) error { return nil })
+
+				if expected, actual := storeErr, err; expected != actual {
+					t.Errorf("Expected error %v %s but got %v", expected, nameFor(options), actual)
+				}
+			}
+		})
+	})
+}
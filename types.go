@@ -0,0 +1,85 @@
+package covertree
+
+import "github.com/mandykoh/go-covertree/typed"
+
+// Analysis describes the shape of the data in a Tree, as estimated by Analyze.
+// See typed.Analysis.
+type Analysis = typed.Analysis
+
+// Cluster represents a group of items in a Tree which are covered by a single
+// centre item.
+type Cluster = typed.Cluster[interface{}]
+
+// DBSCANResult describes a clustering of the items in a Tree by DBSCAN.
+type DBSCANResult = typed.DBSCANResult[interface{}]
+
+// Dendrogram represents a single-linkage hierarchical clustering of items, as
+// a sequence of merges between clusters in order of increasing distance.
+type Dendrogram = typed.Dendrogram[interface{}]
+
+// DistanceFunc represents a function which defines the distance between two
+// items.
+type DistanceFunc = typed.DistanceFunc[interface{}]
+
+// Edge represents an edge of a minimum spanning tree, as returned by
+// Tree.MinimumSpanningTree.
+type Edge = typed.Edge[interface{}]
+
+// HistogramBin represents a count of distances which fall within a range.
+type HistogramBin = typed.HistogramBin
+
+// InsertError describes the failure to insert an item into a tree.
+type InsertError = typed.InsertError[interface{}]
+
+// InsertErrors describes the failures to insert items into a tree by InsertAll
+// or InsertFrom, in no particular order.
+type InsertErrors = typed.InsertErrors[interface{}]
+
+// ItemWithDistance represents an item and its distance from some other
+// predetermined item, as defined by a DistanceFunc.
+type ItemWithDistance = typed.ItemWithDistance[interface{}]
+
+// Kernel is a function which weights the contribution of an item to a density
+// estimate. See typed.Kernel.
+type Kernel = typed.Kernel
+
+// LevelsWithItems represents a set of child items of a parent item, separated
+// into their levels.
+type LevelsWithItems = typed.LevelsWithItems[interface{}]
+
+// MatchFunc represents a function which determines whether an item should be
+// accepted as a result of a search.
+type MatchFunc = typed.MatchFunc[interface{}]
+
+// Merge represents the joining of two clusters in a Dendrogram.
+type Merge = typed.Merge
+
+// NearestIterator iterates over the items of a Tree in order of increasing
+// distance from a query item.
+type NearestIterator = typed.NearestIterator[interface{}]
+
+// Pair represents a pair of items from two trees, as returned by ClosestPairs.
+type Pair = typed.Pair[interface{}]
+
+// ProgressFunc represents a function which is notified of the progress of a
+// bulk operation.
+type ProgressFunc = typed.ProgressFunc
+
+// EpanechnikovKernel weights items by one minus the square of their distance
+// relative to the bandwidth, such that items beyond the bandwidth contribute
+// nothing.
+func EpanechnikovKernel(distance, bandwidth float64) float64 {
+	return typed.EpanechnikovKernel(distance, bandwidth)
+}
+
+// GaussianKernel weights items according to a Gaussian function of their
+// distance, with the bandwidth as the standard deviation.
+func GaussianKernel(distance, bandwidth float64) float64 {
+	return typed.GaussianKernel(distance, bandwidth)
+}
+
+// UniformKernel weights all items within the bandwidth equally, and items
+// beyond the bandwidth not at all.
+func UniformKernel(distance, bandwidth float64) float64 {
+	return typed.UniformKernel(distance, bandwidth)
+}
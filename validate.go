@@ -0,0 +1,72 @@
+package covertree
+
+import "github.com/mandykoh/go-covertree/typed"
+
+// ViolationKind identifies the invariant of a tree which a Violation breaks.
+type ViolationKind = typed.ViolationKind
+
+const (
+	// CoveringViolation indicates an item which is further from its parent
+	// than is permitted at its level, or further from one of its ancestors
+	// than the levels of the ancestor’s children permit.
+	CoveringViolation = typed.CoveringViolation
+
+	// SeparationViolation indicates an item which is no further than the
+	// distance for its level from another item at the same or a higher level.
+	SeparationViolation = typed.SeparationViolation
+
+	// LevelViolation indicates an item which is not at a lower level than its
+	// parent.
+	LevelViolation = typed.LevelViolation
+
+	// DuplicateViolation indicates an item which appears more than once in the
+	// tree.
+	DuplicateViolation = typed.DuplicateViolation
+
+	// ReachabilityViolation indicates a root item which is not at the tree’s
+	// root level.
+	ReachabilityViolation = typed.ReachabilityViolation
+)
+
+// Violation describes an item in a tree which breaks one of the tree’s
+// invariants.
+//
+// Item is the offending item, as stored beneath Parent at Level. Parent is nil
+// for root items, in which case ParentLevel is not meaningful.
+//
+// For covering and separation violations, Other is the item which the
+// offending item is too far from or too close to (which may be Parent), and
+// Distance is the distance between them.
+type Violation struct {
+	Kind        ViolationKind
+	Item        interface{}
+	Level       int
+	Parent      interface{}
+	ParentLevel int
+	Other       interface{}
+	Distance    float64
+}
+
+// Validate checks the structure of the tree, as loaded from its Store, and
+// returns any violations of its invariants which are found, as for
+// typed.Tree.Validate.
+func (t *Tree) Validate() (violations []Violation, err error) {
+	typedViolations, err := t.tree.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range typedViolations {
+		violations = append(violations, Violation{
+			Kind:        v.Kind,
+			Item:        v.Item,
+			Level:       v.Level,
+			Parent:      parentItem(v.Parent),
+			ParentLevel: v.ParentLevel,
+			Other:       v.Other,
+			Distance:    v.Distance,
+		})
+	}
+
+	return violations, nil
+}
@@ -0,0 +1,45 @@
+package covertree
+
+import (
+	"testing"
+)
+
+func TestTreeValidate(t *testing.T) {
+
+	t.Run("reports only separation violations for trees built by insertion", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		_, _ = insertPoints(randomPoints(1000), tree)
+
+		violations, err := tree.Validate()
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		for _, v := range violations {
+			if v.Kind != SeparationViolation {
+				t.Errorf("Expected only separation violations but got %+v", v)
+			}
+		}
+	})
+
+	t.Run("reports violations with untyped parents", func(t *testing.T) {
+		store := newTestStore()
+		tree, _ := NewTreeWithStore(store, 2, 8, distanceBetweenPoints)
+		root, child := &Point{0, 0, 0}, &Point{3, 0, 0}
+		_ = store.AddItem(root, nil, 3)
+		_ = store.AddItem(child, root, 0)
+
+		violations, err := tree.Validate()
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		expected := []Violation{{CoveringViolation, child, 0, root, 3, root, 3}}
+		if len(violations) != len(expected) {
+			t.Fatalf("Expected violations %+v but got %+v", expected, violations)
+		}
+		if violations[0] != expected[0] {
+			t.Errorf("Expected violation %+v but got %+v", expected[0], violations[0])
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package covertree
+
+import "github.com/mandykoh/go-covertree/typed"
+
+// SkipChildren may be returned by a WalkFunc to indicate that the children of
+// the visited item should not be walked. It is not returned as an error by
+// Walk.
+var SkipChildren = typed.SkipChildren
+
+// StopWalk may be returned by a WalkFunc to indicate that no further items
+// should be walked. It is not returned as an error by Walk.
+var StopWalk = typed.StopWalk
+
+// WalkFunc represents a function which is called for each item visited by
+// Walk, along with its parent (which is nil for root items) and the level at
+// which it is stored.
+type WalkFunc func(item, parent interface{}, level int) error
+
+// WalkOrder determines the order in which Walk visits the items of a tree.
+type WalkOrder = typed.WalkOrder
+
+const (
+	// DepthFirst visits each item before its children, and its children (and
+	// their descendants) before its subsequent siblings.
+	DepthFirst = typed.DepthFirst
+
+	// BreadthFirst visits all the roots of the tree, then all of their
+	// children, then all of their children’s children, and so on.
+	BreadthFirst = typed.BreadthFirst
+)
+
+// WalkOptions specifies how WalkWithOptions traverses a tree, as for
+// typed.WalkOptions.
+type WalkOptions = typed.WalkOptions
+
+// Walk calls the visit function for every item in the tree, in depth-first
+// order, as for typed.Tree.Walk.
+func (t *Tree) Walk(visit WalkFunc) error {
+	return t.WalkWithOptions(WalkOptions{}, visit)
+}
+
+// WalkWithOptions calls the visit function for every item in the tree, as for
+// Walk, traversing the tree in the manner specified by the options.
+func (t *Tree) WalkWithOptions(options WalkOptions, visit WalkFunc) error {
+	return t.tree.WalkWithOptions(options, func(item interface{}, parent *interface{}, level int) error {
+		return visit(item, parentItem(parent), level)
+	})
+}
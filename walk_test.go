@@ -0,0 +1,35 @@
+package covertree
+
+import (
+	"testing"
+)
+
+func TestTreeWalk(t *testing.T) {
+
+	t.Run("visits roots with a nil parent and other items with their parent", func(t *testing.T) {
+		tree := NewInMemoryTree(2, 1000.0, distanceBetweenPoints)
+		_, _ = insertPoints(randomPoints(100), tree)
+		visited := make(map[interface{}]bool)
+		roots := 0
+
+		err := tree.Walk(func(item, parent interface{}, level int) error {
+			if parent == nil {
+				roots++
+			} else if !visited[parent.(*Point)] {
+				t.Errorf("Expected parent %v of %v to be visited before it", parent, item)
+			}
+			visited[item.(*Point)] = true
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("Expected success but got error: %v", err)
+		}
+		if roots == 0 {
+			t.Errorf("Expected roots to be visited with a nil parent")
+		}
+		if expected, actual := 100, len(visited); expected != actual {
+			t.Errorf("Expected %d items to be visited but got %d", expected, actual)
+		}
+	})
+}